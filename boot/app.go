@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/jesse0michael/pkg/config"
@@ -16,10 +18,17 @@ type Runner[T any] interface {
 	Close() error
 }
 
+// Reloader can be implemented by a Runner to react to config changes
+// when the app is created WithConfigWatch.
+type Reloader[T any] interface {
+	Reload(ctx context.Context, cfg T) error
+}
+
 type App[T any] struct {
 	ctx     context.Context
 	cancel  context.CancelCauseFunc
 	cfg     T
+	watcher *config.Watcher[T]
 	closers []func(context.Context) error
 }
 
@@ -30,18 +39,41 @@ func NewApp[T any](opts ...Option) *App[T] {
 	}
 
 	ctx, cancel := Context()
-	cfg, err := config.New[T](o.configOpts...)
-	if err != nil {
-		cancel(err)
+	app := &App[T]{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	var provenance config.Provenance
+	configOpts := append(o.configOpts, config.WithProvenance(&provenance))
+	if o.configWatch {
+		w, err := config.NewWatcher[T](ctx, configOpts...)
+		if err != nil {
+			exitOnHelp[T](err, o.configOpts)
+			cancel(err)
+		} else {
+			app.watcher = w
+			app.cfg = w.Load()
+			w.Subscribe(func(cfg T) {
+				if loggerCfg, ok := structHas[logger.Config](cfg); ok {
+					logger.SetLevel(loggerCfg)
+				}
+			})
+		}
+	} else {
+		cfg, err := config.New[T](configOpts...)
+		if err != nil {
+			exitOnHelp[T](err, o.configOpts)
+			cancel(err)
+		}
+		app.cfg = cfg
 	}
+	cfg := app.cfg
 
 	loggerCfg, _ := structHas[logger.Config](cfg)
 	logger.NewLogger(loggerCfg)
-
-	app := &App[T]{
-		ctx:    ctx,
-		cancel: cancel,
-		cfg:    cfg,
+	if ctx.Err() == nil {
+		slog.InfoContext(ctx, "config loaded", "config", config.Redact(cfg), "sources", provenance.Origins())
 	}
 
 	appConfig, hasApp := structHas[config.AppConfig](cfg)
@@ -59,6 +91,18 @@ func NewApp[T any](opts ...Option) *App[T] {
 	return app
 }
 
+// exit is swapped out in tests.
+var exit = os.Exit
+
+// exitOnHelp prints CLI help for the config and exits when the CLI args ask for it.
+func exitOnHelp[T any](err error, opts []config.Option) {
+	if !errors.Is(err, config.ErrHelp) {
+		return
+	}
+	_ = config.WriteHelp(os.Stdout, filepath.Base(os.Args[0]), config.Docs[T](opts...))
+	exit(0)
+}
+
 func (a *App[T]) Context() context.Context {
 	return a.ctx
 }
@@ -67,17 +111,44 @@ func (a *App[T]) Cancel(cause error) {
 	a.cancel(cause)
 }
 
+// Config returns the current config. When the app is created WithConfigWatch
+// it reflects the latest successful reload.
+func (a *App[T]) Config() T {
+	if a.watcher != nil {
+		return a.watcher.Load()
+	}
+	return a.cfg
+}
+
+// OnConfigChange registers f to be called with the new config after every
+// reload that changes it. It is a no-op unless the app is created WithConfigWatch.
+func (a *App[T]) OnConfigChange(f func(T)) {
+	if a.watcher != nil {
+		a.watcher.Subscribe(f)
+	}
+}
+
 func (a *App[T]) Run(runners ...Runner[T]) error {
 	if err := a.ctx.Err(); err != nil {
 		return err
 	}
 
+	for _, runner := range runners {
+		if r, ok := runner.(Reloader[T]); ok {
+			a.OnConfigChange(func(cfg T) {
+				if err := r.Reload(a.ctx, cfg); err != nil {
+					slog.ErrorContext(a.ctx, "failed to reload runner", "err", err)
+				}
+			})
+		}
+	}
+
 	var wg sync.WaitGroup
 	for _, runner := range runners {
 		wg.Add(1)
 		go func(r Runner[T]) {
 			defer wg.Done()
-			if err := r.Run(a.ctx, a.cfg); err != nil {
+			if err := r.Run(a.ctx, a.Config()); err != nil {
 				a.cancel(err)
 			}
 		}(runner)
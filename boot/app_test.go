@@ -0,0 +1,25 @@
+package boot
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNewApp_Help(t *testing.T) {
+	type testConfig struct {
+		Host string `envconfig:"HOST" default:"localhost" help:"server host"`
+	}
+	orig := os.Args
+	os.Args = []string{"test-app", "--help"}
+	t.Cleanup(func() { os.Args = orig })
+
+	code := -1
+	exit = func(c int) { code = c }
+	t.Cleanup(func() { exit = os.Exit })
+
+	app := NewApp[testConfig]()
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	app.Cancel(nil)
+}
@@ -3,7 +3,8 @@ package boot
 import "github.com/jesse0michael/pkg/config"
 
 type options struct {
-	configOpts []config.Option
+	configOpts  []config.Option
+	configWatch bool
 }
 
 // Option configures NewApp.
@@ -17,9 +18,33 @@ func WithConfigPrefix(prefix string) Option {
 }
 
 // WithConfigFile adds a config file to be loaded. Files are applied in order,
-// so later files override earlier ones. Supports JSON and YAML.
+// so later files override earlier ones. Supports JSON, YAML, TOML, HCL and .env.
 func WithConfigFile(path string) Option {
 	return func(o *options) {
 		o.configOpts = append(o.configOpts, config.WithFile(path))
 	}
 }
+
+// WithConfigDir loads every supported config file in a directory in lexical order.
+func WithConfigDir(dir string) Option {
+	return func(o *options) {
+		o.configOpts = append(o.configOpts, config.WithDir(dir))
+	}
+}
+
+// WithConfigEnvironmentOverlay applies config.<ENVIRONMENT>.yaml after config.yaml
+// (and likewise for every config file).
+func WithConfigEnvironmentOverlay() Option {
+	return func(o *options) {
+		o.configOpts = append(o.configOpts, config.WithEnvironmentOverlay())
+	}
+}
+
+// WithConfigWatch reloads the config when a config file changes. Runners that
+// implement Reloader are notified of the new config, and logger level changes
+// are applied without a restart.
+func WithConfigWatch() Option {
+	return func(o *options) {
+		o.configWatch = true
+	}
+}
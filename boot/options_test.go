@@ -19,3 +19,30 @@ func TestWithConfigFile(t *testing.T) {
 		t.Fatalf("expected 1 config option, got %d", len(o.configOpts))
 	}
 }
+
+func TestWithConfigDir(t *testing.T) {
+	var o options
+	WithConfigDir("test-dir")(&o)
+
+	if len(o.configOpts) != 1 {
+		t.Fatalf("expected 1 config option, got %d", len(o.configOpts))
+	}
+}
+
+func TestWithConfigEnvironmentOverlay(t *testing.T) {
+	var o options
+	WithConfigEnvironmentOverlay()(&o)
+
+	if len(o.configOpts) != 1 {
+		t.Fatalf("expected 1 config option, got %d", len(o.configOpts))
+	}
+}
+
+func TestWithConfigWatch(t *testing.T) {
+	var o options
+	WithConfigWatch()(&o)
+
+	if !o.configWatch {
+		t.Fatal("expected config watch to be enabled")
+	}
+}
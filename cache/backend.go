@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/cache/v9"
+)
+
+// ErrNotFound is returned by a Backend when a key isn't cached.
+var ErrNotFound = errors.New("cache: key not found")
+
+// Backend stores encoded cache values by key.
+type Backend interface {
+	// Get returns the value stored for key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value for key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// TTLBackend is a Backend that can report how long the entries it returns have
+// left to live.
+type TTLBackend interface {
+	Backend
+	// GetWithTTL returns the value stored for key and its remaining time to
+	// live, 0 when it doesn't expire, or ErrNotFound.
+	GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error)
+}
+
+// RedisCacheBackend adapts a go-redis/cache, such as the one NewCache returns,
+// into a Backend.
+func RedisCacheBackend(c *cache.Cache) Backend {
+	return redisCacheBackend{cache: c}
+}
+
+type redisCacheBackend struct {
+	cache *cache.Cache
+}
+
+func (b redisCacheBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	if err := b.cache.Get(ctx, key, &value); err != nil {
+		if errors.Is(err, cache.ErrCacheMiss) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	// A disabled or bypassed ResilientCache reports an empty value, not a miss.
+	if len(value) == 0 {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+func (b redisCacheBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.cache.Set(&cache.Item{Ctx: ctx, Key: key, Value: value, TTL: ttl})
+}
+
+func (b redisCacheBackend) Delete(ctx context.Context, keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		errs = append(errs, b.cache.Delete(ctx, key))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/jesse0michael/pkg/resilience"
+	"github.com/sony/gobreaker"
+)
+
+// ErrBreakerOpen is reported by a Breaker's health check while it's open. The
+// cache is bypassed rather than failing calls while the breaker is open, so
+// it's a degraded state: handlers.HandleHealth reports it without failing.
+var ErrBreakerOpen error = breakerOpenError{}
+
+type breakerOpenError struct{}
+
+func (breakerOpenError) Error() string { return "cache: circuit breaker open" }
+
+// Degraded marks the error as a degraded, rather than unhealthy, state.
+func (breakerOpenError) Degraded() bool { return true }
+
+// Breaker is a circuit breaker tuned by the Config breaker settings. It records
+// its state (0 closed, 1 half-open, 2 open) as the cache.breaker.state metric
+// and the calls it rejects as the cache.breaker.rejected metric, and it
+// implements handlers.HealthChecker. Close unregisters the state metric.
+type Breaker struct {
+	*gobreaker.CircuitBreaker
+	name    string
+	breaker *resilience.Breaker
+}
+
+// NewBreaker creates a circuit breaker named name.
+func NewBreaker(name string, cfg Config, opts ...Option) *Breaker {
+	o := newOptions(opts)
+	breaker := resilience.NewBreaker("cache", "cache.breaker.name", resilience.Config{
+		Timeout:      cfg.BreakerTimeout,
+		Failures:     cfg.BreakerFailures,
+		FailureRatio: cfg.BreakerFailureRatio,
+		MinRequests:  cfg.BreakerMinRequests,
+		Interval:     cfg.BreakerInterval,
+		MaxRequests:  cfg.BreakerMaxRequests,
+	}, resilience.WithMeterProvider(o.meterProvider))
+	return &Breaker{
+		CircuitBreaker: breaker.CircuitBreaker(name),
+		name:           name,
+		breaker:        breaker,
+	}
+}
+
+// Close stops recording the breaker state metric.
+func (b *Breaker) Close() error {
+	return b.breaker.Close()
+}
+
+// Execute runs req if the breaker accepts it, counting the calls it rejects.
+func (b *Breaker) Execute(ctx context.Context, req func() (interface{}, error)) (interface{}, error) {
+	return b.breaker.Execute(ctx, b.name, req)
+}
+
+// Healthy reports ErrBreakerOpen, a degraded state, while the breaker is open.
+// A half-open breaker is probing for recovery and is reported healthy.
+func (b *Breaker) Healthy(context.Context) error {
+	if b.State() == gobreaker.StateOpen {
+		return ErrBreakerOpen
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewBreaker_ReadyToTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		successes int
+		failures  int
+		wantState gobreaker.State
+	}{
+		{
+			name:      "default trips after 5 consecutive failures",
+			cfg:       Config{},
+			failures:  6,
+			wantState: gobreaker.StateOpen,
+		},
+		{
+			name:      "below consecutive failures",
+			cfg:       Config{BreakerFailures: 3},
+			failures:  3,
+			wantState: gobreaker.StateClosed,
+		},
+		{
+			name:      "consecutive failures",
+			cfg:       Config{BreakerFailures: 3},
+			failures:  4,
+			wantState: gobreaker.StateOpen,
+		},
+		{
+			name:      "failure ratio",
+			cfg:       Config{BreakerFailures: 100, BreakerFailureRatio: 0.5, BreakerMinRequests: 4},
+			successes: 2,
+			failures:  2,
+			wantState: gobreaker.StateOpen,
+		},
+		{
+			name:      "failure ratio below min requests",
+			cfg:       Config{BreakerFailures: 100, BreakerFailureRatio: 0.5, BreakerMinRequests: 10},
+			successes: 2,
+			failures:  2,
+			wantState: gobreaker.StateClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker("test", tt.cfg)
+			for range tt.successes {
+				_, _ = b.Execute(t.Context(), func() (interface{}, error) { return nil, nil })
+			}
+			for range tt.failures {
+				_, _ = b.Execute(t.Context(), func() (interface{}, error) { return nil, errors.New("test-error") })
+			}
+			if got := b.State(); got != tt.wantState {
+				t.Errorf("Breaker.State() = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestBreaker_Healthy(t *testing.T) {
+	b := NewBreaker("test", Config{BreakerFailures: 1, BreakerTimeout: time.Hour})
+	if err := b.Healthy(t.Context()); err != nil {
+		t.Errorf("Breaker.Healthy() closed error = %v", err)
+	}
+	for range 2 {
+		_, _ = b.Execute(t.Context(), func() (interface{}, error) { return nil, errors.New("test-error") })
+	}
+	if err := b.Healthy(t.Context()); !errors.Is(err, ErrBreakerOpen) {
+		t.Errorf("Breaker.Healthy() open error = %v, want ErrBreakerOpen", err)
+	}
+	var degraded interface{ Degraded() bool }
+	if err := b.Healthy(t.Context()); !errors.As(err, &degraded) || !degraded.Degraded() {
+		t.Errorf("Breaker.Healthy() open error = %v, want degraded", err)
+	}
+}
+
+func TestBreaker_Metrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	b := NewBreaker("test", Config{BreakerFailures: 1, BreakerTimeout: time.Hour},
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	for range 5 {
+		_, _ = b.Execute(t.Context(), func() (interface{}, error) { return nil, errors.New("test-error") })
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				got[m.Name] = data.DataPoints[0].Value
+			case metricdata.Gauge[int64]:
+				got[m.Name] = data.DataPoints[0].Value
+			}
+		}
+	}
+	if got["cache.breaker.rejected"] != 3 {
+		t.Errorf("cache.breaker.rejected = %d, want 3", got["cache.breaker.rejected"])
+	}
+	if got["cache.breaker.state"] != int64(gobreaker.StateOpen) {
+		t.Errorf("cache.breaker.state = %d, want %d", got["cache.breaker.state"], gobreaker.StateOpen)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	rm = metricdata.ResourceMetrics{}
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if data, ok := m.Data.(metricdata.Gauge[int64]); ok && m.Name == "cache.breaker.state" && len(data.DataPoints) > 0 {
+				t.Errorf("cache.breaker.state recorded after Close")
+			}
+		}
+	}
+}
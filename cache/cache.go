@@ -3,23 +3,69 @@ package cache
 import (
 	"time"
 
-	"github.com/go-redis/cache/v8"
-	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/cache/v9"
+	"github.com/redis/go-redis/v9"
 )
 
 type Config struct {
-	Enabled        bool          `envconfig:"CACHE_ENABLED" default:"true"`
-	Size           int           `envconfig:"CACHE_SIZE" default:"10000"`
-	LocalTTL       time.Duration `envconfig:"CACHE_LOCAL_TTL" default:"5m"`
-	TTL            time.Duration `envconfig:"CACHE_TTL" default:"1h"`
-	Timeout        time.Duration `envconfig:"CACHE_TIMEOUT" default:"500ms"`
-	BreakerTimeout time.Duration `envconfig:"CACHE_BREAKER_TIMEOUT" default:"60s"`
+	Enabled             bool          `envconfig:"CACHE_ENABLED" default:"true"`
+	Namespace           string        `envconfig:"CACHE_NAMESPACE"`
+	Size                int           `envconfig:"CACHE_SIZE" default:"10000"`
+	LocalTTL            time.Duration `envconfig:"CACHE_LOCAL_TTL" default:"5m"`
+	TTL                 time.Duration `envconfig:"CACHE_TTL" default:"1h"`
+	StaleTTL            time.Duration `envconfig:"CACHE_STALE_TTL"`
+	Timeout             time.Duration `envconfig:"CACHE_TIMEOUT" default:"500ms"`
+	BreakerTimeout      time.Duration `envconfig:"CACHE_BREAKER_TIMEOUT" default:"60s"`
+	BreakerFailures     uint32        `envconfig:"CACHE_BREAKER_FAILURES" default:"5"`
+	BreakerFailureRatio float64       `envconfig:"CACHE_BREAKER_FAILURE_RATIO"`
+	BreakerMinRequests  uint32        `envconfig:"CACHE_BREAKER_MIN_REQUESTS" default:"10"`
+	BreakerInterval     time.Duration `envconfig:"CACHE_BREAKER_INTERVAL"`
+	BreakerMaxRequests  uint32        `envconfig:"CACHE_BREAKER_MAX_REQUESTS" default:"1"`
 }
 
-// NewCache returns a go-redis/cache using the Config object with a breaker wrapped redis client
-func NewCache(cfg Config, r *redis.Client) *cache.Cache {
-	return cache.New(&cache.Options{
-		Redis:      NewRedisBreaker(cfg, r),
+// NewCache returns a go-redis/cache using the Config object with a breaker wrapped redis client.
+// The client can be a single node, Sentinel failover or Cluster client.
+// With WithInvalidator, writes and deletes are published so other instances
+// evict them from their local cache.
+func NewCache(cfg Config, r redis.UniversalClient, opts ...Option) *cache.Cache {
+	return NewCacheWithBreaker(cfg, NewRedisBreaker(cfg, r, opts...))
+}
+
+// NewCacheWithBreaker returns a go-redis/cache over an existing ResilientCache.
+// The ResilientCache evicts the keys of invalidated tags from the cache's local
+// TinyLFU layer. When the ResilientCache has an Invalidator, the keys other
+// instances publish are evicted from it too, until the Invalidator is closed.
+func NewCacheWithBreaker(cfg Config, r *ResilientCache) *cache.Cache {
+	opts := &cache.Options{
+		Redis:      r,
 		LocalCache: cache.NewTinyLFU(cfg.Size, cfg.LocalTTL),
-	})
+	}
+	if r.opts.codec != nil || r.opts.compressAbove > 0 {
+		s := newSerializer(r.opts)
+		if r.opts.codec == nil {
+			s.codec = MsgpackCodec
+		}
+		// Values without the codec magic byte, like ones written before
+		// switching to codecs, are read in the go-redis/cache format.
+		legacy := cache.New(&cache.Options{})
+		opts.Marshal = func(v interface{}) ([]byte, error) {
+			version, body, err := s.marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return append([]byte{codecMagic, version}, body...), nil
+		}
+		opts.Unmarshal = func(b []byte, v interface{}) error {
+			if len(b) < 2 || b[0] != codecMagic {
+				return legacy.Unmarshal(b, v)
+			}
+			return s.unmarshal(b[1], b[2:], v)
+		}
+	}
+	c := cache.New(opts)
+	r.local = GoRedisLocalCache(c)
+	if inv := r.opts.invalidator; inv != nil {
+		go func() { _ = inv.Listen(inv.ctx, r.local) }()
+	}
+	return c
 }
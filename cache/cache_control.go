@@ -2,6 +2,11 @@ package cache
 
 import (
 	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/marcw/cachecontrol"
 )
@@ -10,10 +15,38 @@ type contextKey string
 
 const CacheControlContextKey = contextKey("cacheControl")
 
+// ParseCacheControl is HTTP middleware that parses the request Cache-Control
+// header, or Pragma: no-cache without one, into the context so the cache
+// honors it.
+func ParseCacheControl(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Cache-Control")
+		if header == "" && strings.EqualFold(r.Header.Get("Pragma"), "no-cache") {
+			header = "no-cache"
+		}
+		if header != "" {
+			ctx := context.WithValue(r.Context(), CacheControlContextKey, cachecontrol.Parse(header))
+			r = r.WithContext(ctx)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withoutCacheControl hides the request cache control settings from calls that
+// coordinate requests rather than cache them.
+func withoutCacheControl(ctx context.Context) context.Context {
+	return context.WithValue(ctx, CacheControlContextKey, nil)
+}
+
+func cacheControl(ctx context.Context) (cachecontrol.CacheControl, bool) {
+	cc, ok := ctx.Value(CacheControlContextKey).(cachecontrol.CacheControl)
+	return cc, ok
+}
+
 // NoCache is a Cache Control helper method that will check context for cache control settings
 // and retrieve the no-cache value
 func NoCache(ctx context.Context) bool {
-	if cc, ok := ctx.Value(CacheControlContextKey).(cachecontrol.CacheControl); ok {
+	if cc, ok := cacheControl(ctx); ok {
 		b, _ := cc.NoCache()
 		return b
 	}
@@ -23,8 +56,56 @@ func NoCache(ctx context.Context) bool {
 // NoStore is a Cache Control helper method that will check context for cache control settings
 // and retrieve the no-store value
 func NoStore(ctx context.Context) bool {
-	if cc, ok := ctx.Value(CacheControlContextKey).(cachecontrol.CacheControl); ok {
+	if cc, ok := cacheControl(ctx); ok {
 		return cc.NoStore()
 	}
 	return false
 }
+
+// MaxAge is a Cache Control helper method that will check context for cache control settings
+// and retrieve the max-age value: the oldest cached entry the request accepts
+func MaxAge(ctx context.Context) (time.Duration, bool) {
+	return timedDirective(ctx, "max-age")
+}
+
+// MaxStale is a Cache Control helper method that will check context for cache control settings
+// and retrieve the max-stale value: how long past expiry a cached entry is accepted.
+// A max-stale without a value accepts any stale entry
+func MaxStale(ctx context.Context) (time.Duration, bool) {
+	if cc, ok := cacheControl(ctx); ok {
+		if v, ok := cc["max-stale"]; ok && v == "" {
+			return math.MaxInt64, true
+		}
+	}
+	return timedDirective(ctx, "max-stale")
+}
+
+// StaleWhileRevalidate is a Cache Control helper method that will check context for cache control settings
+// and retrieve the stale-while-revalidate value: how long past expiry a cached entry is served
+// while it's refreshed in the background
+func StaleWhileRevalidate(ctx context.Context) (time.Duration, bool) {
+	return timedDirective(ctx, "stale-while-revalidate")
+}
+
+// StaleIfError is a Cache Control helper method that will check context for cache control settings
+// and retrieve the stale-if-error value: how long past expiry a cached entry is served when
+// it can't be refreshed
+func StaleIfError(ctx context.Context) (time.Duration, bool) {
+	return timedDirective(ctx, "stale-if-error")
+}
+
+func timedDirective(ctx context.Context, directive string) (time.Duration, bool) {
+	cc, ok := cacheControl(ctx)
+	if !ok {
+		return 0, false
+	}
+	v, ok := cc[directive]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(v)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
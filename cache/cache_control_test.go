@@ -2,7 +2,11 @@ package cache
 
 import (
 	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/marcw/cachecontrol"
 )
@@ -80,3 +84,117 @@ func TestNoStore(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheControlDurations(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		directive func(context.Context) (time.Duration, bool)
+		want      time.Duration
+		wantOK    bool
+	}{
+		{
+			name:      "max-age",
+			header:    "max-age=60",
+			directive: MaxAge,
+			want:      time.Minute,
+			wantOK:    true,
+		},
+		{
+			name:      "max-age not found",
+			header:    "no-store",
+			directive: MaxAge,
+		},
+		{
+			name:      "max-age invalid",
+			header:    "max-age=test",
+			directive: MaxAge,
+		},
+		{
+			name:      "max-stale",
+			header:    "max-stale=30",
+			directive: MaxStale,
+			want:      30 * time.Second,
+			wantOK:    true,
+		},
+		{
+			name:      "max-stale without value",
+			header:    "max-stale",
+			directive: MaxStale,
+			want:      math.MaxInt64,
+			wantOK:    true,
+		},
+		{
+			name:      "stale-while-revalidate",
+			header:    "max-age=60, stale-while-revalidate=10",
+			directive: StaleWhileRevalidate,
+			want:      10 * time.Second,
+			wantOK:    true,
+		},
+		{
+			name:      "stale-if-error",
+			header:    "stale-if-error=86400",
+			directive: StaleIfError,
+			want:      24 * time.Hour,
+			wantOK:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(t.Context(), CacheControlContextKey, cachecontrol.Parse(tt.header))
+			got, ok := tt.directive(ctx)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := MaxAge(t.Context()); ok {
+		t.Error("MaxAge() without cache control got ok")
+	}
+}
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      http.Header
+		wantNoCache bool
+		wantMaxAge  time.Duration
+	}{
+		{
+			name:   "no header",
+			header: http.Header{},
+		},
+		{
+			name:        "cache control",
+			header:      http.Header{"Cache-Control": {"no-cache, max-age=60"}},
+			wantNoCache: true,
+			wantMaxAge:  time.Minute,
+		},
+		{
+			name:        "pragma no cache",
+			header:      http.Header{"Pragma": {"no-cache"}},
+			wantNoCache: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotNoCache bool
+			var gotMaxAge time.Duration
+			handler := ParseCacheControl(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				gotNoCache = NoCache(r.Context())
+				gotMaxAge, _ = MaxAge(r.Context())
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header = tt.header
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if gotNoCache != tt.wantNoCache {
+				t.Errorf("NoCache() got = %v, want %v", gotNoCache, tt.wantNoCache)
+			}
+			if gotMaxAge != tt.wantMaxAge {
+				t.Errorf("MaxAge() got = %v, want %v", gotMaxAge, tt.wantMaxAge)
+			}
+		})
+	}
+}
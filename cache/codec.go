@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes cached values. Encoded values start with a version byte
+// holding the ID of the codec that wrote them and whether they're compressed,
+// so values written by one codec are still read after switching to another.
+type Codec interface {
+	// ID identifies the codec in the version byte, between 1 and 63. IDs up
+	// to 15 are reserved for the codecs of this package.
+	ID() byte
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSONCodec encodes values with encoding/json. It's the default codec.
+	JSONCodec Codec = jsonCodec{}
+	// MsgpackCodec encodes values with msgpack, like the go-redis/cache.
+	MsgpackCodec Codec = msgpackCodec{}
+	// ProtoCodec encodes values that implement proto.Message.
+	ProtoCodec Codec = protoCodec{}
+	// GobCodec encodes values with encoding/gob.
+	GobCodec Codec = gobCodec{}
+)
+
+var codecs = map[byte]Codec{
+	JSONCodec.ID():    JSONCodec,
+	MsgpackCodec.ID(): MsgpackCodec,
+	ProtoCodec.ID():   ProtoCodec,
+	GobCodec.ID():     GobCodec,
+}
+
+const (
+	// versionCompressed flags zstd compressed values in the version byte.
+	versionCompressed byte = 0x80
+	// versionCodec masks the codec ID in the version byte. The remaining bit
+	// is never set, so the version byte can't be mistaken for the '{' of a
+	// JSON value written before codecs.
+	versionCodec byte = 0x3f
+	// codecMagic precedes the version byte of values in the go-redis/cache
+	// format, whose legacy msgpack values can start with any other byte. 0xc1
+	// is never used by msgpack and can't start UTF-8 text, so a value written
+	// before codecs is never mistaken for one in the codec format.
+	codecMagic byte = 0xc1
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte { return 1 }
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ID() byte { return 2 }
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) { return msgpack.Marshal(v) }
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+type protoCodec struct{}
+
+func (protoCodec) ID() byte { return 3 }
+
+func (protoCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("failed to marshal %T: not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal decodes into a proto.Message or a pointer to one, allocating the
+// message when it's nil.
+func (protoCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Pointer {
+			if rv.Elem().IsNil() {
+				rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+			}
+			m, ok = rv.Elem().Interface().(proto.Message)
+		}
+	}
+	if !ok {
+		return fmt.Errorf("failed to unmarshal into %T: not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) ID() byte { return 4 }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// The zstd encoder and decoder are safe for concurrent EncodeAll and
+// DecodeAll calls, so they're shared.
+var (
+	zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+		e, _ := zstd.NewWriter(nil)
+		return e
+	})
+	zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+		d, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+		return d
+	})
+)
+
+// serializer encodes values with a codec and compresses the ones of at least
+// compressAbove bytes with zstd.
+type serializer struct {
+	codec         Codec
+	compressAbove int
+}
+
+func newSerializer(o options) serializer {
+	s := serializer{codec: o.codec, compressAbove: o.compressAbove}
+	if s.codec == nil {
+		s.codec = JSONCodec
+	}
+	return s
+}
+
+// marshal encodes v, returning its version byte and body.
+func (s serializer) marshal(v any) (byte, []byte, error) {
+	body, err := s.codec.Marshal(v)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to encode cache value: %w", err)
+	}
+	version := s.codec.ID() & versionCodec
+	if s.compressAbove > 0 && len(body) >= s.compressAbove {
+		version |= versionCompressed
+		body = zstdEncoder().EncodeAll(body, nil)
+	}
+	return version, body, nil
+}
+
+// unmarshal decodes body into v with the codec and compression of version.
+func (s serializer) unmarshal(version byte, body []byte, v any) error {
+	if version&^(versionCodec|versionCompressed) != 0 {
+		return errors.New("failed to decode cache value: unknown version")
+	}
+	codec, ok := codecs[version&versionCodec]
+	if s.codec.ID() == version&versionCodec {
+		codec, ok = s.codec, true
+	}
+	if !ok {
+		return fmt.Errorf("failed to decode cache value: unknown codec %d", version&versionCodec)
+	}
+	if version&versionCompressed != 0 {
+		var err error
+		if body, err = zstdDecoder().DecodeAll(body, nil); err != nil {
+			return fmt.Errorf("failed to decompress cache value: %w", err)
+		}
+	}
+	if err := codec.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode cache value: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/cache/v9"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testValue struct {
+	Name  string
+	Count int
+}
+
+func TestSerializer(t *testing.T) {
+	long := strings.Repeat("test-value", 100)
+	tests := []struct {
+		name           string
+		codec          Codec
+		compressAbove  int
+		value          string
+		wantCompressed bool
+	}{
+		{name: "json", codec: JSONCodec, value: "test-value"},
+		{name: "msgpack", codec: MsgpackCodec, value: "test-value"},
+		{name: "gob", codec: GobCodec, value: "test-value"},
+		{name: "below threshold", codec: JSONCodec, compressAbove: 100, value: "test-value"},
+		{name: "compressed", codec: MsgpackCodec, compressAbove: 100, value: long, wantCompressed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSerializer(options{codec: tt.codec, compressAbove: tt.compressAbove})
+			version, body, err := s.marshal(testValue{Name: tt.value, Count: 42})
+			if err != nil {
+				t.Fatalf("serializer.marshal() error = %v", err)
+			}
+			if version&versionCodec != tt.codec.ID() {
+				t.Errorf("serializer.marshal() codec = %d, want %d", version&versionCodec, tt.codec.ID())
+			}
+			if compressed := version&versionCompressed != 0; compressed != tt.wantCompressed {
+				t.Errorf("serializer.marshal() compressed = %v, want %v", compressed, tt.wantCompressed)
+			}
+			if tt.wantCompressed && len(body) >= len(tt.value) {
+				t.Errorf("serializer.marshal() body = %d bytes, want fewer than %d", len(body), len(tt.value))
+			}
+
+			// Values are read whatever the configured codec is.
+			var got testValue
+			if err := newSerializer(options{}).unmarshal(version, body, &got); err != nil {
+				t.Fatalf("serializer.unmarshal() error = %v", err)
+			}
+			if got != (testValue{Name: tt.value, Count: 42}) {
+				t.Errorf("serializer.unmarshal() = %v", got)
+			}
+		})
+	}
+}
+
+func TestSerializer_Proto(t *testing.T) {
+	s := newSerializer(options{codec: ProtoCodec})
+	version, body, err := s.marshal(wrapperspb.String("test-value"))
+	if err != nil {
+		t.Fatalf("serializer.marshal() error = %v", err)
+	}
+	var got *wrapperspb.StringValue
+	if err := s.unmarshal(version, body, &got); err != nil {
+		t.Fatalf("serializer.unmarshal() error = %v", err)
+	}
+	if !proto.Equal(got, wrapperspb.String("test-value")) {
+		t.Errorf("serializer.unmarshal() = %v, want test-value", got)
+	}
+	if _, _, err := s.marshal("test-value"); err == nil {
+		t.Error("serializer.marshal() of a non proto.Message error = nil")
+	}
+}
+
+func TestSerializer_UnknownVersion(t *testing.T) {
+	s := newSerializer(options{})
+	for _, version := range []byte{0, 63, '{'} {
+		var got string
+		if err := s.unmarshal(version, []byte(`"test-value"`), &got); err == nil {
+			t.Errorf("serializer.unmarshal(%d) error = nil", version)
+		}
+	}
+}
+
+func TestCache_Codec(t *testing.T) {
+	ctx := t.Context()
+	cfg := Config{Enabled: true, TTL: time.Hour}
+	backend := NewMemoryBackend(cfg)
+	loader := func(_ context.Context, key string) (*wrapperspb.StringValue, error) {
+		return wrapperspb.String("test-loaded"), nil
+	}
+
+	protoCache := New[string, *wrapperspb.StringValue]("test", cfg, backend, WithCodec(ProtoCodec), WithCompression(1))
+	if err := protoCache.Set(ctx, "test-key", wrapperspb.String("test-cached")); err != nil {
+		t.Fatalf("Cache.Set() error = %v", err)
+	}
+	got, err := protoCache.GetOrLoad(ctx, "test-key", loader)
+	if err != nil || got.GetValue() != "test-cached" {
+		t.Errorf("Cache.GetOrLoad() = %v, %v, want test-cached", got, err)
+	}
+
+	// Entries written with JSON before the deploy are read after switching
+	// to msgpack.
+	jsonCache := New[string, testValue]("test", cfg, backend)
+	if err := jsonCache.Set(ctx, "test-json", testValue{Name: "test-value", Count: 1}); err != nil {
+		t.Fatalf("Cache.Set() error = %v", err)
+	}
+	msgpackCache := New[string, testValue]("test", cfg, backend, WithCodec(MsgpackCodec))
+	e, ok := msgpackCache.get(ctx, "test:test-json")
+	if !ok || e.Value != (testValue{Name: "test-value", Count: 1}) || e.Expiry.IsZero() {
+		t.Errorf("Cache.get() = %v, %v", e, ok)
+	}
+
+	// So are JSON entries written before codecs.
+	_ = backend.Set(ctx, "test:test-legacy", []byte(`{"v":{"Name":"test-legacy","Count":2}}`), time.Hour)
+	if e, ok := msgpackCache.get(ctx, "test:test-legacy"); !ok || e.Value.Name != "test-legacy" {
+		t.Errorf("Cache.get() legacy = %v, %v", e, ok)
+	}
+}
+
+func TestNewCache_Codec(t *testing.T) {
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	cfg := Config{Enabled: true, Size: 100, LocalTTL: time.Minute}
+	ctx := t.Context()
+
+	legacy := NewCache(cfg, rc)
+	if err := legacy.Set(&cache.Item{Ctx: ctx, Key: "test-legacy", Value: testValue{Name: "test-legacy"}, TTL: time.Hour}); err != nil {
+		t.Fatalf("Cache.Set() error = %v", err)
+	}
+
+	c := NewCache(cfg, rc, WithCodec(GobCodec), WithCompression(1))
+	if err := c.Set(&cache.Item{Ctx: ctx, Key: "test-key", Value: testValue{Name: "test-value"}, TTL: time.Hour}); err != nil {
+		t.Fatalf("Cache.Set() error = %v", err)
+	}
+	raw, _ := s.Get("test-key")
+	if raw[0] != codecMagic || raw[1] != GobCodec.ID()|versionCompressed {
+		t.Errorf("version bytes = %x, want %x", raw[:2], []byte{codecMagic, GobCodec.ID() | versionCompressed})
+	}
+
+	for key, want := range map[string]string{"test-key": "test-value", "test-legacy": "test-legacy"} {
+		var got testValue
+		c.DeleteFromLocalCache(key)
+		if err := c.Get(ctx, key, &got); err != nil || got.Name != want {
+			t.Errorf("Cache.Get(%s) = %v, %v, want %s", key, got, err, want)
+		}
+	}
+
+	var missing testValue
+	if err := c.Get(ctx, "test-missing", &missing); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("Cache.Get() error = %v, want cache miss", err)
+	}
+}
+
+func TestNewCache_CodecLegacy(t *testing.T) {
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	cfg := Config{Enabled: true, Size: 100, LocalTTL: time.Minute}
+	ctx := t.Context()
+	legacy := NewCache(cfg, rc)
+	c := NewCache(cfg, rc, WithCodec(MsgpackCodec))
+
+	// Values of every msgpack type, including the positive fixints that share
+	// their first byte with codec IDs, written before codecs.
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{name: "nil", value: (*testValue)(nil)},
+		{name: "false", value: false},
+		{name: "true", value: true},
+		{name: "positive fixint 0", value: 0},
+		{name: "positive fixint 1", value: 1},
+		{name: "positive fixint 2", value: 2},
+		{name: "positive fixint 3", value: 3},
+		{name: "positive fixint 4", value: 4},
+		{name: "positive fixint 127", value: 127},
+		{name: "negative fixint", value: -1},
+		{name: "uint 8", value: uint8(200)},
+		{name: "uint 16", value: uint16(60000)},
+		{name: "uint 32", value: uint32(4000000000)},
+		{name: "uint 64", value: uint64(1 << 63)},
+		{name: "int 8", value: int8(-100)},
+		{name: "int 16", value: int16(-30000)},
+		{name: "int 32", value: int32(-2000000000)},
+		{name: "int 64", value: int64(-1 << 62)},
+		{name: "float 32", value: float32(1.5)},
+		{name: "float 64", value: 1.5},
+		{name: "fixstr", value: "test-value"},
+		{name: "str 8", value: strings.Repeat("a", 100)},
+		{name: "str 16", value: strings.Repeat("a", 1000)},
+		{name: "bin", value: []byte{0x01, 0x00}},
+		{name: "fixarray", value: []int{1, 2, 3}},
+		{name: "array 16", value: make([]int, 20)},
+		{name: "fixmap", value: map[string]int{"a": 1}},
+		{name: "map 16", value: func() map[int]int {
+			m := map[int]int{}
+			for i := range 20 {
+				m[i] = i
+			}
+			return m
+		}()},
+		{name: "struct", value: testValue{Name: "test-value", Count: 2}},
+		{name: "ext time", value: time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := legacy.Set(&cache.Item{Ctx: ctx, Key: "test-key", Value: tt.value, TTL: time.Hour}); err != nil {
+				t.Fatalf("Cache.Set() error = %v", err)
+			}
+			c.DeleteFromLocalCache("test-key")
+
+			got := reflect.New(reflect.TypeOf(tt.value))
+			if err := c.Get(ctx, "test-key", got.Interface()); err != nil {
+				t.Fatalf("Cache.Get() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Elem().Interface(), tt.value) {
+				t.Errorf("Cache.Get() = %#v, want %#v", got.Elem().Interface(), tt.value)
+			}
+		})
+	}
+}
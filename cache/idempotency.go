@@ -0,0 +1,261 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jesse0michael/pkg/auth"
+)
+
+// Idempotency is HTTP middleware that processes requests carrying an
+// Idempotency-Key header at most once. The first response for a key is stored
+// in a Backend and replayed, with an Idempotent-Replayed header, on retries. A
+// request with the key of one still in progress gets 409 Conflict, and a retry
+// with a different body than the first request gets 422 Unprocessable Entity.
+// Keys are scoped by the caller, method and path. Request bodies larger than
+// the max body size get 413 Request Entity Too Large.
+type Idempotency struct {
+	locker  *Locker
+	backend Backend
+	ttl     time.Duration
+	lockTTL time.Duration
+	maxBody int64
+	scope   func(*http.Request) string
+}
+
+const (
+	defaultIdempotencyLockTTL = 30 * time.Second
+	defaultIdempotencyMaxBody = 1 << 20
+)
+
+// idempotentResponse is a stored response with the fingerprint of the request
+// body that produced it.
+type idempotentResponse struct {
+	cachedResponse
+	Fingerprint string `json:"f"`
+}
+
+// IdempotencyOption configures an Idempotency middleware.
+type IdempotencyOption func(*Idempotency)
+
+// WithIdempotencyTTL sets how long responses are replayed, 24h by default.
+func WithIdempotencyTTL(ttl time.Duration) IdempotencyOption {
+	return func(i *Idempotency) {
+		i.ttl = ttl
+	}
+}
+
+// WithIdempotencyLockTTL sets how long a request's lock outlives it if the
+// instance processing it dies, 30s by default. The lock is extended while the
+// request is processed. A non-positive ttl keeps the default.
+func WithIdempotencyLockTTL(ttl time.Duration) IdempotencyOption {
+	return func(i *Idempotency) {
+		i.lockTTL = ttl
+	}
+}
+
+// WithIdempotencyMaxBody sets the largest request body, in bytes, that is
+// read to fingerprint a request, 1MiB by default. Larger requests are rejected
+// with 413 Request Entity Too Large. A non-positive size keeps the default.
+func WithIdempotencyMaxBody(size int64) IdempotencyOption {
+	return func(i *Idempotency) {
+		i.maxBody = size
+	}
+}
+
+// WithIdempotencyScope sets the func that scopes keys to a caller, so callers
+// can't replay each other's responses by reusing a key. Keys are scoped by
+// auth.Subject by default, which leaves unauthenticated requests sharing one
+// scope.
+func WithIdempotencyScope(scope func(*http.Request) string) IdempotencyOption {
+	return func(i *Idempotency) {
+		i.scope = scope
+	}
+}
+
+// NewIdempotency creates idempotency middleware that locks keys with locker
+// and stores responses in backend.
+func NewIdempotency(locker *Locker, backend Backend, opts ...IdempotencyOption) *Idempotency {
+	i := &Idempotency{
+		locker:  locker,
+		backend: backend,
+		ttl:     24 * time.Hour,
+		lockTTL: defaultIdempotencyLockTTL,
+		maxBody: defaultIdempotencyMaxBody,
+		scope: func(r *http.Request) string {
+			subject, _ := auth.Subject(r.Context())
+			return subject
+		},
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	// The lock is extended every half TTL, which needs a positive interval.
+	if i.lockTTL <= 0 {
+		i.lockTTL = defaultIdempotencyLockTTL
+	}
+	if i.maxBody <= 0 {
+		i.maxBody = defaultIdempotencyMaxBody
+	}
+	return i
+}
+
+// Middleware processes requests with an Idempotency-Key at most once. Requests
+// without one are passed through. When the key can't be locked, the request is
+// rejected with 503 Service Unavailable rather than risk processing it twice.
+// 5xx responses aren't stored, so the request can be retried.
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKey := r.Header.Get("Idempotency-Key")
+		if idempotencyKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Cache control settings mustn't skip the stored responses.
+		ctx := withoutCacheControl(r.Context())
+		fingerprint, err := fingerprintBody(w, r, i.maxBody)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "failed to read idempotent request body", "err", err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		key := fmt.Sprintf("idempotency:%s:%s:%s:%s", i.scope(r), r.Method, r.URL.Path, idempotencyKey)
+		if resp, ok := i.get(ctx, key); ok {
+			replay(w, resp, fingerprint)
+			return
+		}
+
+		lock, err := i.locker.TryAcquire(ctx, key, i.lockTTL)
+		if errors.Is(err, ErrLockNotAcquired) {
+			http.Error(w, "request with this Idempotency-Key is in progress", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to lock idempotency key", "err", err, "key", key)
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		// The lock is kept and the response stored even if the caller goes
+		// away, so its retry is replayed rather than processed again.
+		bgCtx := context.WithoutCancel(ctx)
+		defer func() {
+			if err := lock.Release(bgCtx); err != nil {
+				slog.WarnContext(ctx, "failed to release idempotency key", "err", err, "key", key)
+			}
+		}()
+
+		// The first request may have finished between the lookup and the lock.
+		if resp, ok := i.get(ctx, key); ok {
+			replay(w, resp, fingerprint)
+			return
+		}
+
+		stop := i.keepAlive(bgCtx, lock)
+		defer stop()
+		rec := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		resp := cachedResponse{Status: rec.status, Header: rec.header, Body: rec.body.Bytes(), Stored: time.Now()}
+		if resp.Status < http.StatusInternalServerError {
+			if err := i.set(bgCtx, key, idempotentResponse{cachedResponse: resp, Fingerprint: fingerprint}); err != nil {
+				slog.ErrorContext(ctx, "failed to store idempotent response", "err", err, "key", key)
+			}
+		}
+		writeResponse(w, resp)
+	})
+}
+
+func (i *Idempotency) get(ctx context.Context, key string) (idempotentResponse, bool) {
+	var resp idempotentResponse
+	b, err := i.backend.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.WarnContext(ctx, "failed to get idempotent response", "err", err, "key", key)
+		}
+		return resp, false
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		slog.WarnContext(ctx, "failed to decode idempotent response", "err", err, "key", key)
+		return resp, false
+	}
+	return resp, true
+}
+
+func (i *Idempotency) set(ctx context.Context, key string, resp idempotentResponse) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotent response: %w", err)
+	}
+	return i.backend.Set(ctx, key, b, i.ttl)
+}
+
+// keepAlive extends lock until the returned func is called, so slow requests
+// keep their key locked.
+func (i *Idempotency) keepAlive(ctx context.Context, lock *Lock) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(i.lockTTL / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lock.Extend(ctx, i.lockTTL); err != nil {
+					slog.WarnContext(ctx, "failed to extend idempotency key", "err", err, "key", lock.Key())
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// fingerprintBody hashes the request body, up to maxBody bytes, leaving it
+// readable by the handler.
+func fingerprintBody(w http.ResponseWriter, r *http.Request, maxBody int64) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return hex.EncodeToString(sha256.New().Sum(nil)), nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replay writes the stored response, or 422 Unprocessable Entity when the key
+// is reused with a different request body.
+func replay(w http.ResponseWriter, resp idempotentResponse, fingerprint string) {
+	if resp.Fingerprint != fingerprint {
+		http.Error(w, "Idempotency-Key reused with a different request body", http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	writeResponse(w, resp.cachedResponse)
+}
+
+func writeResponse(w http.ResponseWriter, resp cachedResponse) {
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(resp.Body)
+}
@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jesse0michael/pkg/auth"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestIdempotency_Middleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		keys         []string
+		status       int
+		wantStatus   int
+		wantBody     string
+		wantReplayed bool
+		wantCalls    int32
+	}{
+		{
+			name:       "no key",
+			keys:       []string{"", ""},
+			status:     http.StatusCreated,
+			wantStatus: http.StatusCreated,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name:         "replayed",
+			keys:         []string{"test-key", "test-key"},
+			status:       http.StatusCreated,
+			wantStatus:   http.StatusCreated,
+			wantBody:     "test-body",
+			wantReplayed: true,
+			wantCalls:    1,
+		},
+		{
+			name:       "different keys",
+			keys:       []string{"test-key-1", "test-key-2"},
+			status:     http.StatusCreated,
+			wantStatus: http.StatusCreated,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name:         "client error replayed",
+			keys:         []string{"test-key", "test-key"},
+			status:       http.StatusBadRequest,
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     "test-body",
+			wantReplayed: true,
+			wantCalls:    1,
+		},
+		{
+			name:       "server error retried",
+			keys:       []string{"test-key", "test-key"},
+			status:     http.StatusInternalServerError,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locker, _ := newTestLocker(t, Config{Enabled: true})
+			var calls atomic.Int32
+			h := NewIdempotency(locker, NewMemoryBackend(Config{Enabled: true})).Middleware(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls.Add(1)
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte("test-body"))
+				}))
+
+			var w *httptest.ResponseRecorder
+			for _, key := range tt.keys {
+				r := httptest.NewRequest(http.MethodPost, "/test", nil)
+				if key != "" {
+					r.Header.Set("Idempotency-Key", key)
+				}
+				w = httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status got = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body got = %q, want %q", got, tt.wantBody)
+			}
+			if got := w.Header().Get("Idempotent-Replayed") == "true"; got != tt.wantReplayed {
+				t.Errorf("replayed got = %v, want %v", got, tt.wantReplayed)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler calls got = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotency_Middleware_Request(t *testing.T) {
+	type request struct {
+		subject string
+		body    string
+	}
+	tests := []struct {
+		name         string
+		opts         []IdempotencyOption
+		requests     []request
+		wantStatus   int
+		wantReplayed bool
+		wantCalls    int32
+	}{
+		{
+			name:         "same body replayed",
+			requests:     []request{{subject: "test-user", body: "test-body"}, {subject: "test-user", body: "test-body"}},
+			wantStatus:   http.StatusCreated,
+			wantReplayed: true,
+			wantCalls:    1,
+		},
+		{
+			name:       "different body rejected",
+			requests:   []request{{subject: "test-user", body: "test-body"}, {subject: "test-user", body: "test-other"}},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCalls:  1,
+		},
+		{
+			name:       "scoped by subject",
+			requests:   []request{{subject: "test-user", body: "test-body"}, {subject: "test-other", body: "test-body"}},
+			wantStatus: http.StatusCreated,
+			wantCalls:  2,
+		},
+		{
+			name: "custom scope",
+			opts: []IdempotencyOption{WithIdempotencyScope(func(r *http.Request) string {
+				return r.Header.Get("X-Tenant")
+			})},
+			requests:     []request{{subject: "test-user", body: "test-body"}, {subject: "test-other", body: "test-body"}},
+			wantStatus:   http.StatusCreated,
+			wantReplayed: true,
+			wantCalls:    1,
+		},
+		{
+			name:       "body too large",
+			opts:       []IdempotencyOption{WithIdempotencyMaxBody(4)},
+			requests:   []request{{subject: "test-user", body: "test-body"}},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "non-positive lock ttl",
+			opts:         []IdempotencyOption{WithIdempotencyLockTTL(0)},
+			requests:     []request{{subject: "test-user", body: "test-body"}, {subject: "test-user", body: "test-body"}},
+			wantStatus:   http.StatusCreated,
+			wantReplayed: true,
+			wantCalls:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locker, _ := newTestLocker(t, Config{Enabled: true})
+			var calls atomic.Int32
+			h := NewIdempotency(locker, NewMemoryBackend(Config{Enabled: true}), tt.opts...).Middleware(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls.Add(1)
+					body, _ := io.ReadAll(r.Body)
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write(body)
+				}))
+
+			var w *httptest.ResponseRecorder
+			for _, req := range tt.requests {
+				ctx := context.WithValue(t.Context(), auth.SubjectContextKey, req.subject)
+				r := httptest.NewRequestWithContext(ctx, http.MethodPost, "/test", strings.NewReader(req.body))
+				r.Header.Set("Idempotency-Key", "test-key")
+				r.Header.Set("X-Tenant", "test-tenant")
+				w = httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status got = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code == http.StatusCreated && w.Body.String() != "test-body" {
+				t.Errorf("body got = %q, want %q", w.Body.String(), "test-body")
+			}
+			if got := w.Header().Get("Idempotent-Replayed") == "true"; got != tt.wantReplayed {
+				t.Errorf("replayed got = %v, want %v", got, tt.wantReplayed)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler calls got = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotency_Middleware_Canceled(t *testing.T) {
+	locker, s := newTestLocker(t, Config{Enabled: true})
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	var calls atomic.Int32
+	var cancel context.CancelFunc
+	h := NewIdempotency(locker, NewRedisBackend(Config{Enabled: true}, rc)).Middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			// The caller goes away while the request is processed.
+			cancel()
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("test-body"))
+		}))
+
+	ctx, c := context.WithCancel(t.Context())
+	cancel = c
+	r := httptest.NewRequestWithContext(ctx, http.MethodPost, "/test", strings.NewReader("test-body"))
+	r.Header.Set("Idempotency-Key", "test-key")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	r = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("test-body"))
+	r.Header.Set("Idempotency-Key", "test-key")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry got = %d replayed=%q, want replayed 201", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("handler calls got = %d, want 1", got)
+	}
+}
+
+func TestIdempotency_Middleware_Concurrent(t *testing.T) {
+	locker, s := newTestLocker(t, Config{Enabled: true})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := NewIdempotency(locker, NewMemoryBackend(Config{Enabled: true}), WithIdempotencyLockTTL(20*time.Millisecond)).Middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			_, _ = w.Write([]byte("test-body"))
+		}))
+	request := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/test", nil)
+		r.Header.Set("Idempotency-Key", "test-key")
+		return r
+	}
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(first, request())
+	}()
+	<-started
+
+	// The lock is extended past its TTL while the first request runs.
+	s.FastForward(15 * time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	s.FastForward(15 * time.Millisecond)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, request())
+	if w.Code != http.StatusConflict {
+		t.Errorf("concurrent status got = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	close(release)
+	<-done
+	if first.Code != http.StatusOK || !strings.Contains(first.Body.String(), "test-body") {
+		t.Errorf("first response got = %d %q", first.Code, first.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, request())
+	if w.Code != http.StatusOK || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry got = %d replayed=%q, want replayed 200", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/cache/v9"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// LocalCache is a local cache an Invalidator evicts keys from. A MemoryBackend
+// is a LocalCache. Local caches that implement Clear() are cleared when the
+// Invalidator reconnects, since invalidations published while it was
+// disconnected are lost.
+type LocalCache interface {
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// GoRedisLocalCache adapts the local cache of a go-redis/cache, such as the
+// one NewCache returns, into a LocalCache.
+func GoRedisLocalCache(c *cache.Cache) LocalCache {
+	return goRedisLocalCache{cache: c}
+}
+
+type goRedisLocalCache struct {
+	cache *cache.Cache
+}
+
+func (l goRedisLocalCache) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		l.cache.DeleteFromLocalCache(key)
+	}
+	return nil
+}
+
+// invalidation is the message an Invalidator publishes.
+type invalidation struct {
+	Source string   `json:"source"`
+	Keys   []string `json:"keys"`
+}
+
+// Invalidator broadcasts cache key invalidations over a Redis pub/sub channel
+// so every instance can evict written and deleted keys from its local cache.
+// Invalidations dropped because they couldn't be published or decoded, or
+// because the subscription was interrupted, are counted by the
+// cache.invalidations.dropped metric.
+type Invalidator struct {
+	rc           redis.UniversalClient
+	channel      string
+	source       string
+	retryBackoff time.Duration
+	dropped      metric.Int64Counter
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+// InvalidatorOption configures an Invalidator.
+type InvalidatorOption func(*Invalidator)
+
+// WithInvalidationChannel sets the Redis channel invalidations are published
+// on, cache:invalidations by default.
+func WithInvalidationChannel(channel string) InvalidatorOption {
+	return func(i *Invalidator) {
+		i.channel = channel
+	}
+}
+
+// WithInvalidatorMeterProvider sets the meter provider that records dropped
+// invalidations, the global meter provider by default.
+func WithInvalidatorMeterProvider(mp metric.MeterProvider) InvalidatorOption {
+	return func(i *Invalidator) {
+		var err error
+		if i.dropped, err = newDroppedCounter(mp); err != nil {
+			otel.Handle(err)
+		}
+	}
+}
+
+// NewInvalidator creates an Invalidator that publishes on the rc client.
+func NewInvalidator(rc redis.UniversalClient, opts ...InvalidatorOption) *Invalidator {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	i := &Invalidator{
+		rc:           rc,
+		channel:      "cache:invalidations",
+		source:       hex.EncodeToString(b),
+		retryBackoff: time.Second,
+	}
+	i.ctx, i.cancel = context.WithCancel(context.Background())
+	var err error
+	if i.dropped, err = newDroppedCounter(otel.GetMeterProvider()); err != nil {
+		otel.Handle(err)
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Close stops the listeners NewCache and NewCacheWithBreaker started for their
+// local caches.
+func (i *Invalidator) Close() error {
+	i.cancel()
+	return nil
+}
+
+func newDroppedCounter(mp metric.MeterProvider) (metric.Int64Counter, error) {
+	return mp.Meter("github.com/jesse0michael/pkg/cache").Int64Counter("cache.invalidations.dropped",
+		metric.WithDescription("Number of cache invalidations that were not delivered"))
+}
+
+// Publish tells every other instance to evict keys from its local cache.
+func (i *Invalidator) Publish(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(invalidation{Source: i.source, Keys: keys})
+	if err != nil {
+		i.drop(ctx, "encode", len(keys))
+		return fmt.Errorf("failed to encode cache invalidation: %w", err)
+	}
+	if err := i.rc.Publish(ctx, i.channel, b).Err(); err != nil {
+		i.drop(ctx, "publish", len(keys))
+		return fmt.Errorf("failed to publish cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// Listen evicts the keys other instances publish from local until ctx is
+// done. It resubscribes after connection errors, clearing local if it
+// implements Clear().
+func (i *Invalidator) Listen(ctx context.Context, local LocalCache) error {
+	ps := i.rc.Subscribe(ctx, i.channel)
+	defer ps.Close()
+	// Receive blocks on the connection, so closing the subscription is what
+	// stops Listen when ctx is done.
+	stop := context.AfterFunc(ctx, func() { _ = ps.Close() })
+	defer stop()
+
+	var subscribed bool
+	for {
+		msg, err := ps.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.WarnContext(ctx, "failed to receive cache invalidations, reconnecting", "err", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(i.retryBackoff):
+			}
+			continue
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			if msg.Kind != "subscribe" {
+				continue
+			}
+			if subscribed {
+				i.drop(ctx, "reconnect", 1)
+				if c, ok := local.(interface{ Clear() }); ok {
+					c.Clear()
+				}
+			}
+			subscribed = true
+		case *redis.Message:
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				slog.WarnContext(ctx, "failed to decode cache invalidation", "err", err)
+				i.drop(ctx, "decode", 1)
+				continue
+			}
+			if inv.Source == i.source {
+				continue
+			}
+			if err := local.Delete(ctx, inv.Keys...); err != nil {
+				slog.WarnContext(ctx, "failed to evict invalidated cache keys", "err", err)
+				i.drop(ctx, "evict", len(inv.Keys))
+			}
+		}
+	}
+}
+
+func (i *Invalidator) drop(ctx context.Context, reason string, n int) {
+	i.dropped.Add(ctx, int64(n), metric.WithAttributes(attribute.String("reason", reason)))
+}
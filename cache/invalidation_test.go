@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/cache/v9"
+	"github.com/redis/go-redis/v9"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// listen runs inv.Listen for local until the test ends and waits for it to
+// subscribe.
+func listen(t *testing.T, s *miniredis.Miniredis, inv *Invalidator, local LocalCache) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = inv.Listen(ctx, local)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	eventually(t, func() bool { return s.PubSubNumSub(inv.channel)[inv.channel] > 0 })
+}
+
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	for range 100 {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met")
+}
+
+func droppedInvalidations(t *testing.T, reader sdkmetric.Reader, reason string) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != "cache.invalidations.dropped" {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				if v, _ := dp.Attributes.Value("reason"); v.AsString() == reason {
+					return dp.Value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestInvalidator(t *testing.T) {
+	s := miniredis.RunT(t)
+	cfg := Config{Enabled: true, LocalTTL: time.Minute}
+
+	// Instance a writes through its Redis backend, instance b reads through a
+	// tiered backend with a local cache.
+	rcA := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	writer := NewRedisBackend(cfg, rcA, WithInvalidator(NewInvalidator(rcA)))
+
+	rcB := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	invB := NewInvalidator(rcB)
+	local := NewMemoryBackend(cfg)
+	reader := NewTieredBackend(cfg, local, NewRedisBackend(cfg, rcB, WithInvalidator(invB)))
+	listen(t, s, invB, local)
+
+	if err := writer.Set(t.Context(), "test-key", []byte("test-value"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reader.Get(t.Context(), "test-key"); string(got) != "test-value" {
+		t.Fatalf("reader.Get() = %s, want test-value", got)
+	}
+
+	if err := writer.Set(t.Context(), "test-key", []byte("test-updated"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		got, _ := reader.Get(t.Context(), "test-key")
+		return string(got) == "test-updated"
+	})
+
+	// Writes through the reader don't evict its own local cache.
+	if err := reader.Set(t.Context(), "test-key-2", []byte("test-value"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := local.Get(t.Context(), "test-key-2"); err != nil {
+		t.Errorf("local.Get() error = %v, want own write kept", err)
+	}
+
+	if err := writer.Delete(t.Context(), "test-key"); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		_, err := local.Get(t.Context(), "test-key")
+		return err != nil
+	})
+}
+
+func TestNewCache_Invalidator(t *testing.T) {
+	s := miniredis.RunT(t)
+	cfg := Config{Enabled: true, Size: 100, LocalTTL: time.Minute}
+	newCache := func() *cache.Cache {
+		rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+		inv := NewInvalidator(rc)
+		t.Cleanup(func() { _ = inv.Close() })
+		return NewCache(cfg, rc, WithInvalidator(inv))
+	}
+	writer, reader := newCache(), newCache()
+	eventually(t, func() bool { return s.PubSubNumSub("cache:invalidations")["cache:invalidations"] == 2 })
+
+	set := func(value string) {
+		t.Helper()
+		if err := writer.Set(&cache.Item{Ctx: t.Context(), Key: "test-key", Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func() string {
+		var got string
+		_ = reader.Get(t.Context(), "test-key", &got)
+		return got
+	}
+	set("test-value")
+	if got := get(); got != "test-value" {
+		t.Fatalf("reader.Get() = %s, want test-value", got)
+	}
+	set("test-updated")
+	eventually(t, func() bool { return get() == "test-updated" })
+}
+
+func TestResilientCache_InvalidateSkipsLocks(t *testing.T) {
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	r := NewRedisBreaker(Config{Enabled: true}, rc, WithInvalidator(NewInvalidator(rc)))
+	ps := rc.Subscribe(t.Context(), "cache:invalidations")
+	t.Cleanup(func() { _ = ps.Close() })
+	if _, err := ps.Receive(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewLocker(r).TryAcquire(t.Context(), "test-key", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(t.Context(), "test-key", "test-value", time.Minute).Err(); err != nil {
+		t.Fatal(err)
+	}
+	msg := <-ps.Channel()
+	var inv invalidation
+	if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Keys) != 1 || inv.Keys[0] != "test-key" {
+		t.Errorf("published keys = %v, want [test-key]", inv.Keys)
+	}
+}
+
+func TestInvalidator_Dropped(t *testing.T) {
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	metrics := sdkmetric.NewManualReader()
+	inv := NewInvalidator(rc, WithInvalidatorMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(metrics))))
+	inv.retryBackoff = 10 * time.Millisecond
+	local := NewMemoryBackend(Config{Enabled: true})
+	_ = local.Set(t.Context(), "test-key", []byte("test-value"), 0)
+	listen(t, s, inv, local)
+
+	s.Publish(inv.channel, "test-invalid")
+	eventually(t, func() bool { return droppedInvalidations(t, metrics, "decode") == 1 })
+
+	s.Close()
+	if err := s.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool { return droppedInvalidations(t, metrics, "reconnect") == 1 })
+	if local.Len() != 0 {
+		t.Errorf("local.Len() = %d, want cleared after reconnect", local.Len())
+	}
+}
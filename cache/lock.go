@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+)
+
+var (
+	// ErrLockNotAcquired is returned when a lock is held by someone else.
+	ErrLockNotAcquired = errors.New("cache: lock not acquired")
+	// ErrLockNotHeld is returned when releasing or extending a lock that expired
+	// or was taken over.
+	ErrLockNotHeld = errors.New("cache: lock not held")
+	// ErrLockDisabled is returned when acquiring a lock from a disabled cache.
+	ErrLockDisabled = errors.New("cache: locks need an enabled cache")
+)
+
+// lockPrefix prefixes lock keys. Lock keys coordinate instances rather than
+// cache values, so writing them publishes no invalidations.
+const lockPrefix = "lock:"
+
+// Release and extend only touch the lock while it still holds the caller's
+// token, so a lock that expired and was acquired by someone else is left alone.
+const (
+	releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+	extendScript  = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+)
+
+// Locker hands out distributed locks stored in Redis through a ResilientCache.
+// Locks ignore the request cache control settings, but need an enabled cache.
+type Locker struct {
+	cache      *ResilientCache
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// LockerOption configures a Locker.
+type LockerOption func(*Locker)
+
+// WithLockBackoff sets the exponential backoff between attempts to acquire a
+// held lock, 10ms up to 500ms by default.
+func WithLockBackoff(minBackoff, maxBackoff time.Duration) LockerOption {
+	return func(l *Locker) {
+		l.minBackoff = minBackoff
+		l.maxBackoff = maxBackoff
+	}
+}
+
+// NewLocker creates a Locker over cache.
+func NewLocker(cache *ResilientCache, opts ...LockerOption) *Locker {
+	l := &Locker{
+		cache:      cache,
+		minBackoff: 10 * time.Millisecond,
+		maxBackoff: 500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Lock is a held distributed lock.
+type Lock struct {
+	locker *Locker
+	key    string
+	token  string
+}
+
+// TryAcquire takes the lock on key for ttl, or returns ErrLockNotAcquired if
+// it's held.
+func (l *Locker) TryAcquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if !l.cache.cfg.Enabled {
+		return nil, ErrLockDisabled
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	lock := &Lock{locker: l, key: lockPrefix + key, token: hex.EncodeToString(b)}
+
+	ok, err := l.cache.SetNX(withoutCacheControl(ctx), lock.key, lock.token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if !ok {
+		return nil, ErrLockNotAcquired
+	}
+	return lock, nil
+}
+
+// Acquire takes the lock on key for ttl, retrying with backoff while it's held
+// until ctx is done.
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = l.minBackoff
+	b.MaxInterval = l.maxBackoff
+	return backoff.Retry(ctx, func() (*Lock, error) {
+		lock, err := l.TryAcquire(ctx, key, ttl)
+		if err != nil && !errors.Is(err, ErrLockNotAcquired) {
+			return nil, backoff.Permanent(err)
+		}
+		return lock, err
+	}, backoff.WithBackOff(b), backoff.WithMaxElapsedTime(0))
+}
+
+// Key returns the locked key.
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token returns the random token that identifies this holder of the lock.
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Release frees the lock, or returns ErrLockNotHeld if it expired.
+func (l *Lock) Release(ctx context.Context) error {
+	n, err := l.locker.cache.Eval(ctx, releaseScript, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Extend resets the lock to expire after ttl, or returns ErrLockNotHeld if it
+// expired.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	n, err := l.locker.cache.Eval(ctx, extendScript, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to extend lock: %w", err)
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
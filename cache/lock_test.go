@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/marcw/cachecontrol"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLocker(t *testing.T, cfg Config) (*Locker, *miniredis.Miniredis) {
+	t.Helper()
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	return NewLocker(NewRedisBreaker(cfg, rc), WithLockBackoff(time.Millisecond, 10*time.Millisecond)), s
+}
+
+func TestLocker_TryAcquire(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		cfg     Config
+		held    bool
+		wantErr error
+	}{
+		{
+			name: "acquired",
+			ctx:  t.Context(),
+			cfg:  Config{Enabled: true},
+		},
+		{
+			name:    "held",
+			ctx:     t.Context(),
+			cfg:     Config{Enabled: true},
+			held:    true,
+			wantErr: ErrLockNotAcquired,
+		},
+		{
+			name: "cache control: no store",
+			ctx:  context.WithValue(t.Context(), CacheControlContextKey, cachecontrol.Parse("no-store")),
+			cfg:  Config{Enabled: true},
+		},
+		{
+			name:    "disabled",
+			ctx:     t.Context(),
+			cfg:     Config{Enabled: false},
+			wantErr: ErrLockDisabled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locker, s := newTestLocker(t, tt.cfg)
+			if tt.held {
+				_ = s.Set("lock:test-key", "test-token")
+			}
+
+			lock, err := locker.TryAcquire(tt.ctx, "test-key", time.Minute)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Locker.TryAcquire() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, _ := s.Get("lock:test-key"); got != lock.Token() {
+				t.Errorf("lock value = %q, want token %q", got, lock.Token())
+			}
+			if ttl := s.TTL("lock:test-key"); ttl != time.Minute {
+				t.Errorf("lock TTL = %v, want 1m", ttl)
+			}
+		})
+	}
+}
+
+func TestLocker_Acquire(t *testing.T) {
+	locker, s := newTestLocker(t, Config{Enabled: true})
+	first, err := locker.Acquire(t.Context(), "test-key", time.Minute)
+	if err != nil {
+		t.Fatalf("Locker.Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := locker.Acquire(ctx, "test-key", time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Locker.Acquire() held error = %v, want context.DeadlineExceeded", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = first.Release(t.Context())
+	}()
+	second, err := locker.Acquire(t.Context(), "test-key", time.Minute)
+	if err != nil {
+		t.Fatalf("Locker.Acquire() after release error = %v", err)
+	}
+	if got, _ := s.Get("lock:test-key"); got != second.Token() {
+		t.Errorf("lock value = %q, want second token", got)
+	}
+}
+
+func TestLock_ReleaseExtend(t *testing.T) {
+	locker, s := newTestLocker(t, Config{Enabled: true})
+	lock, err := locker.TryAcquire(t.Context(), "test-key", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := lock.Extend(t.Context(), time.Hour); err != nil {
+		t.Errorf("Lock.Extend() error = %v", err)
+	}
+	if ttl := s.TTL("lock:test-key"); ttl != time.Hour {
+		t.Errorf("lock TTL = %v, want 1h", ttl)
+	}
+
+	// Someone else took the lock after it expired.
+	_ = s.Set("lock:test-key", "test-token")
+	if err := lock.Extend(t.Context(), time.Hour); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Lock.Extend() taken over error = %v, want ErrLockNotHeld", err)
+	}
+	if err := lock.Release(t.Context()); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Lock.Release() taken over error = %v, want ErrLockNotHeld", err)
+	}
+	if !s.Exists("lock:test-key") {
+		t.Error("Lock.Release() deleted a lock it didn't hold")
+	}
+
+	_ = s.Set("lock:test-key", lock.Token())
+	if err := lock.Release(t.Context()); err != nil {
+		t.Errorf("Lock.Release() error = %v", err)
+	}
+	if s.Exists("lock:test-key") {
+		t.Error("Lock.Release() didn't delete the lock")
+	}
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend is an in-memory Backend that evicts the least recently used
+// entry once it holds Config.Size entries and expires entries after their TTL.
+// It suits tests, single-instance services and the local tier of a
+// TieredBackend.
+type MemoryBackend struct {
+	cfg   Config
+	now   func() time.Time
+	mu    sync.Mutex
+	items map[string]*list.Element
+	lru   *list.List
+}
+
+type memoryItem struct {
+	key    string
+	value  []byte
+	expiry time.Time
+}
+
+// NewMemoryBackend creates an in-memory backend that holds up to cfg.Size
+// entries, or any number when it's 0.
+func NewMemoryBackend(cfg Config) *MemoryBackend {
+	return &MemoryBackend{
+		cfg:   cfg,
+		now:   time.Now,
+		items: map[string]*list.Element{},
+		lru:   list.New(),
+	}
+}
+
+func (m *MemoryBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	value, _, err := m.GetWithTTL(ctx, key)
+	return value, err
+}
+
+// GetWithTTL returns the value stored for key and its remaining time to live,
+// 0 when it doesn't expire.
+func (m *MemoryBackend) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	if NoCache(ctx) || !m.cfg.Enabled {
+		return nil, 0, ErrNotFound
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok {
+		return nil, 0, ErrNotFound
+	}
+	item := el.Value.(*memoryItem)
+	var ttl time.Duration
+	if !item.expiry.IsZero() {
+		ttl = item.expiry.Sub(m.now())
+		if ttl <= 0 {
+			m.remove(el)
+			return nil, 0, ErrNotFound
+		}
+	}
+	m.lru.MoveToFront(el)
+	return item.value, ttl, nil
+}
+
+// Set stores value for key for ttl, or until it's evicted when ttl is 0.
+func (m *MemoryBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if NoStore(ctx) || !m.cfg.Enabled {
+		return nil
+	}
+
+	var expiry time.Time
+	if ttl > 0 {
+		expiry = m.now().Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		el.Value = &memoryItem{key: key, value: value, expiry: expiry}
+		m.lru.MoveToFront(el)
+		return nil
+	}
+	m.items[key] = m.lru.PushFront(&memoryItem{key: key, value: value, expiry: expiry})
+	if m.cfg.Size > 0 && m.lru.Len() > m.cfg.Size {
+		m.remove(m.lru.Back())
+	}
+	return nil
+}
+
+func (m *MemoryBackend) Delete(_ context.Context, keys ...string) error {
+	if !m.cfg.Enabled {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := m.items[key]; ok {
+			m.remove(el)
+		}
+	}
+	return nil
+}
+
+// Clear removes every entry.
+func (m *MemoryBackend) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = map[string]*list.Element{}
+	m.lru.Init()
+}
+
+// Len returns the number of entries held, including expired entries that
+// haven't been read since they expired.
+func (m *MemoryBackend) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
+
+func (m *MemoryBackend) remove(el *list.Element) {
+	m.lru.Remove(el)
+	delete(m.items, el.Value.(*memoryItem).key)
+}
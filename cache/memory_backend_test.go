@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marcw/cachecontrol"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		cfg     Config
+		setup   func(m *MemoryBackend, now *time.Time)
+		key     string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "hit",
+			ctx:  t.Context(),
+			cfg:  Config{Enabled: true},
+			setup: func(m *MemoryBackend, now *time.Time) {
+				_ = m.Set(t.Context(), "test-key", []byte("test-value"), time.Minute)
+			},
+			key:  "test-key",
+			want: "test-value",
+		},
+		{
+			name:    "miss",
+			ctx:     t.Context(),
+			cfg:     Config{Enabled: true},
+			setup:   func(m *MemoryBackend, now *time.Time) {},
+			key:     "test-key",
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "expired",
+			ctx:  t.Context(),
+			cfg:  Config{Enabled: true},
+			setup: func(m *MemoryBackend, now *time.Time) {
+				_ = m.Set(t.Context(), "test-key", []byte("test-value"), time.Minute)
+				*now = now.Add(time.Minute)
+			},
+			key:     "test-key",
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "evicted least recently used",
+			ctx:  t.Context(),
+			cfg:  Config{Enabled: true, Size: 2},
+			setup: func(m *MemoryBackend, now *time.Time) {
+				_ = m.Set(t.Context(), "test-key", []byte("test-value"), 0)
+				_ = m.Set(t.Context(), "test-key-2", []byte("test-value-2"), 0)
+				_, _ = m.Get(t.Context(), "test-key")
+				_ = m.Set(t.Context(), "test-key-3", []byte("test-value-3"), 0)
+			},
+			key:     "test-key-2",
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "deleted",
+			ctx:  t.Context(),
+			cfg:  Config{Enabled: true},
+			setup: func(m *MemoryBackend, now *time.Time) {
+				_ = m.Set(t.Context(), "test-key", []byte("test-value"), 0)
+				_ = m.Delete(t.Context(), "test-key")
+			},
+			key:     "test-key",
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "cache control: no cache",
+			ctx:  context.WithValue(t.Context(), CacheControlContextKey, cachecontrol.Parse("no-cache")),
+			cfg:  Config{Enabled: true},
+			setup: func(m *MemoryBackend, now *time.Time) {
+				_ = m.Set(t.Context(), "test-key", []byte("test-value"), 0)
+			},
+			key:     "test-key",
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "disabled",
+			ctx:  t.Context(),
+			cfg:  Config{Enabled: false},
+			setup: func(m *MemoryBackend, now *time.Time) {
+				_ = m.Set(t.Context(), "test-key", []byte("test-value"), 0)
+			},
+			key:     "test-key",
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			m := NewMemoryBackend(tt.cfg)
+			m.now = func() time.Time { return now }
+			tt.setup(m, &now)
+
+			got, err := m.Get(tt.ctx, tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("MemoryBackend.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MemoryBackend.Get() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type options struct {
+	beta          float64
+	meterProvider metric.MeterProvider
+	invalidator   *Invalidator
+	tagBatchSize  int64
+	codec         Codec
+	compressAbove int
+}
+
+// Option configures a Cache, ResilientCache or RedisBackend.
+type Option func(*options)
+
+func newOptions(opts []Option) options {
+	o := options{
+		beta:          1,
+		meterProvider: otel.GetMeterProvider(),
+		tagBatchSize:  100,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithBeta sets how eagerly Cache entries are refreshed before they expire.
+// Higher values refresh earlier, 0 disables early expiration. The default is 1.
+func WithBeta(beta float64) Option {
+	return func(o *options) {
+		o.beta = beta
+	}
+}
+
+// WithMeterProvider sets the meter provider that records cache metrics,
+// the global meter provider by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = mp
+	}
+}
+
+// WithInvalidator publishes the keys a ResilientCache sets or deletes through
+// inv, so other instances evict them from their local caches.
+func WithInvalidator(inv *Invalidator) Option {
+	return func(o *options) {
+		o.invalidator = inv
+	}
+}
+
+// WithTagBatchSize sets how many keys ResilientCache.InvalidateTag deletes per
+// round trip, 100 by default.
+func WithTagBatchSize(n int64) Option {
+	return func(o *options) {
+		o.tagBatchSize = n
+	}
+}
+
+// WithCodec sets the codec that encodes Cache values, JSONCodec by default,
+// and NewCache values, the go-redis/cache msgpack format by default. Values
+// written by the other codecs of this package are still read.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// WithCompression compresses encoded Cache and NewCache values of at least
+// threshold bytes with zstd.
+func WithCompression(threshold int) Option {
+	return func(o *options) {
+		o.compressAbove = threshold
+	}
+}
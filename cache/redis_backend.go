@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBackend is a Backend that stores entries in Redis through a
+// ResilientCache, so Redis failures trip a circuit breaker instead of
+// slowing every call.
+type RedisBackend struct {
+	cfg   Config
+	cache *ResilientCache
+}
+
+// NewRedisBackend creates a Redis backend. Each call is bounded by
+// cfg.Timeout when it's set.
+func NewRedisBackend(cfg Config, r redis.UniversalClient, opts ...Option) *RedisBackend {
+	return &RedisBackend{
+		cfg:   cfg,
+		cache: NewRedisBreaker(cfg, r, opts...),
+	}
+}
+
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	value, err := b.cache.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// A disabled or bypassed ResilientCache reports an empty value, not a miss.
+	if len(value) == 0 {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+// GetWithTTL returns the value stored for key and its remaining time to live,
+// reading both in a single round trip.
+func (b *RedisBackend) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	if NoCache(ctx) || !b.cfg.Enabled {
+		return nil, 0, ErrNotFound
+	}
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := b.cache.Pipelined(ctx, func(p redis.Pipeliner) error {
+		get = p.Get(ctx, key)
+		pttl = p.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+	value, err := get.Bytes()
+	if errors.Is(err, redis.Nil) || (err == nil && len(value) == 0) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	// PTTL reports -1ns for keys without an expiry.
+	return value, max(pttl.Val(), 0), nil
+}
+
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return b.cache.Set(ctx, key, value, ttl).Err()
+}
+
+func (b *RedisBackend) Delete(ctx context.Context, keys ...string) error {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return b.cache.Del(ctx, keys...).Err()
+}
+
+func (b *RedisBackend) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.cfg.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, b.cfg.Timeout)
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisBackend(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		redisSetup func(*redis.Client)
+		set        bool
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "hit",
+			cfg:        Config{Enabled: true, Timeout: time.Second},
+			redisSetup: func(rc *redis.Client) {},
+			set:        true,
+			want:       "test-value",
+		},
+		{
+			name:       "miss",
+			cfg:        Config{Enabled: true},
+			redisSetup: func(rc *redis.Client) {},
+			wantErr:    ErrNotFound,
+		},
+		{
+			name:       "disabled",
+			cfg:        Config{Enabled: false},
+			redisSetup: func(rc *redis.Client) {},
+			set:        true,
+			wantErr:    ErrNotFound,
+		},
+		{
+			name:       "redis closed",
+			cfg:        Config{Enabled: true},
+			redisSetup: func(rc *redis.Client) { _ = rc.Close() },
+			wantErr:    redis.ErrClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := miniredis.RunT(t)
+			rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+			b := NewRedisBackend(tt.cfg, rc)
+			if tt.set {
+				if err := b.Set(t.Context(), "test-key", []byte("test-value"), time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tt.redisSetup(rc)
+
+			got, err := b.Get(t.Context(), "test-key")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RedisBackend.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RedisBackend.Get() = %s, want %s", got, tt.want)
+			}
+
+			got, ttl, err := b.GetWithTTL(t.Context(), "test-key")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RedisBackend.GetWithTTL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RedisBackend.GetWithTTL() = %s, want %s", got, tt.want)
+			}
+			if tt.want != "" && (ttl <= 0 || ttl > time.Minute) {
+				t.Errorf("RedisBackend.GetWithTTL() ttl = %v, want up to %v", ttl, time.Minute)
+			}
+		})
+	}
+}
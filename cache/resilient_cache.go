@@ -2,47 +2,59 @@ package cache
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
-	"github.com/go-redis/redis/v8"
-	"github.com/sony/gobreaker"
+	"github.com/redis/go-redis/v9"
 )
 
 // ResilientCache is a cache wrapper that implements the go-cache rediser interface.
 // It supports the cache control settings passed in context.
-// https://github.com/go-redis/cache/blob/8756f3baa759d22acfdc1dc67f9fbcc0e21e6332/cache.go#L32
+// https://github.com/go-redis/cache/blob/v9.0.0/cache.go#L32
 type ResilientCache struct {
 	cfg     Config
-	redis   *redis.Client
-	breaker *gobreaker.CircuitBreaker
+	redis   redis.UniversalClient
+	breaker *Breaker
+	opts    options
+	local   LocalCache
 }
 
-func NewRedisBreaker(cfg Config, r *redis.Client) *ResilientCache {
-	settings := gobreaker.Settings{
-		Name:    "cache breaker",
-		Timeout: cfg.BreakerTimeout,
-		OnStateChange: func(name string, from, to gobreaker.State) {
-			slog.With("from", from.String(), "to", to.String()).Warn(fmt.Sprintf("%s changing state", name))
-		},
-	}
+// NewRedisBreaker wraps r in a circuit breaker tuned by the Config breaker settings.
+func NewRedisBreaker(cfg Config, r redis.UniversalClient, opts ...Option) *ResilientCache {
 	return &ResilientCache{
 		cfg:     cfg,
 		redis:   r,
-		breaker: gobreaker.NewCircuitBreaker(settings),
+		breaker: NewBreaker("cache breaker", cfg, opts...),
+		opts:    newOptions(opts),
 	}
 }
 
+// Breaker returns the circuit breaker, to check its state and counts.
+func (r *ResilientCache) Breaker() *Breaker {
+	return r.breaker
+}
+
+// Healthy reports ErrBreakerOpen while the circuit breaker is open. It
+// implements handlers.HealthChecker.
+func (r *ResilientCache) Healthy(ctx context.Context) error {
+	return r.breaker.Healthy(ctx)
+}
+
 func (r *ResilientCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) *redis.StatusCmd {
 	if NoStore(ctx) || !r.cfg.Enabled {
 		return redis.NewStatusResult("", nil)
 	}
 
-	result, err := r.breaker.Execute(func() (interface{}, error) {
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
 		result := r.redis.Set(ctx, key, value, ttl)
 		return result, result.Err()
 	})
+	if err == nil {
+		r.invalidate(ctx, key)
+	}
 	if cmd, ok := result.(*redis.StatusCmd); ok {
 		return cmd
 	}
@@ -54,10 +66,13 @@ func (r *ResilientCache) SetXX(ctx context.Context, key string, value interface{
 		return redis.NewBoolResult(false, nil)
 	}
 
-	result, err := r.breaker.Execute(func() (interface{}, error) {
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
 		result := r.redis.SetXX(ctx, key, value, ttl)
 		return result, result.Err()
 	})
+	if cmd, ok := result.(*redis.BoolCmd); ok && cmd.Val() {
+		r.invalidate(ctx, key)
+	}
 	if cmd, ok := result.(*redis.BoolCmd); ok {
 		return cmd
 	}
@@ -69,10 +84,13 @@ func (r *ResilientCache) SetNX(ctx context.Context, key string, value interface{
 		return redis.NewBoolResult(false, nil)
 	}
 
-	result, err := r.breaker.Execute(func() (interface{}, error) {
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
 		result := r.redis.SetNX(ctx, key, value, ttl)
 		return result, result.Err()
 	})
+	if cmd, ok := result.(*redis.BoolCmd); ok && cmd.Val() {
+		r.invalidate(ctx, key)
+	}
 	if cmd, ok := result.(*redis.BoolCmd); ok {
 		return cmd
 	}
@@ -84,7 +102,7 @@ func (r *ResilientCache) Get(ctx context.Context, key string) *redis.StringCmd {
 		return redis.NewStringResult("", nil)
 	}
 
-	result, err := r.breaker.Execute(func() (interface{}, error) {
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
 		result := r.redis.Get(ctx, key)
 		if result.Err() == redis.Nil {
 			return result, nil
@@ -102,12 +120,150 @@ func (r *ResilientCache) Del(ctx context.Context, keys ...string) *redis.IntCmd
 		return redis.NewIntResult(0, nil)
 	}
 
-	result, err := r.breaker.Execute(func() (interface{}, error) {
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
 		result := r.redis.Del(ctx, keys...)
 		return result, result.Err()
 	})
+	if err == nil {
+		r.invalidate(ctx, keys...)
+	}
 	if cmd, ok := result.(*redis.IntCmd); ok {
 		return cmd
 	}
 	return redis.NewIntResult(0, err)
 }
+
+func (r *ResilientCache) MGet(ctx context.Context, keys ...string) *redis.SliceCmd {
+	if NoCache(ctx) || !r.cfg.Enabled {
+		return redis.NewSliceResult(make([]interface{}, len(keys)), nil)
+	}
+
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+		result := r.redis.MGet(ctx, keys...)
+		return result, result.Err()
+	})
+	if cmd, ok := result.(*redis.SliceCmd); ok {
+		return cmd
+	}
+	return redis.NewSliceResult(nil, err)
+}
+
+func (r *ResilientCache) Incr(ctx context.Context, key string) *redis.IntCmd {
+	if NoStore(ctx) || !r.cfg.Enabled {
+		return redis.NewIntResult(0, nil)
+	}
+
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+		result := r.redis.Incr(ctx, key)
+		return result, result.Err()
+	})
+	if err == nil {
+		r.invalidate(ctx, key)
+	}
+	if cmd, ok := result.(*redis.IntCmd); ok {
+		return cmd
+	}
+	return redis.NewIntResult(0, err)
+}
+
+func (r *ResilientCache) Expire(ctx context.Context, key string, ttl time.Duration) *redis.BoolCmd {
+	if NoStore(ctx) || !r.cfg.Enabled {
+		return redis.NewBoolResult(false, nil)
+	}
+
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+		result := r.redis.Expire(ctx, key, ttl)
+		return result, result.Err()
+	})
+	if cmd, ok := result.(*redis.BoolCmd); ok {
+		return cmd
+	}
+	return redis.NewBoolResult(false, err)
+}
+
+// TTL returns the remaining time to live of key, -2ns when it doesn't exist and
+// -1ns when it has no expiry, matching go-redis.
+func (r *ResilientCache) TTL(ctx context.Context, key string) *redis.DurationCmd {
+	if NoCache(ctx) || !r.cfg.Enabled {
+		return redis.NewDurationResult(-2, nil)
+	}
+
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+		result := r.redis.TTL(ctx, key)
+		return result, result.Err()
+	})
+	if cmd, ok := result.(*redis.DurationCmd); ok {
+		return cmd
+	}
+	return redis.NewDurationResult(0, err)
+}
+
+// Eval runs a Lua script. It ignores the cache control settings, so it suits
+// coordination like locks rather than caching.
+func (r *ResilientCache) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	if !r.cfg.Enabled {
+		return redis.NewCmdResult(nil, redis.Nil)
+	}
+
+	result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+		result := r.redis.Eval(ctx, script, keys, args...)
+		if result.Err() == redis.Nil {
+			return result, nil
+		}
+		return result, result.Err()
+	})
+	if cmd, ok := result.(*redis.Cmd); ok {
+		return cmd
+	}
+	return redis.NewCmdResult(nil, err)
+}
+
+// Pipelined runs the commands queued by fn in a pipeline as a single breaker
+// call. A redis.Nil reply doesn't count as a failure. Keys written in the
+// pipeline aren't invalidated; publish them with the Invalidator.
+func (r *ResilientCache) Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	return r.pipelined(ctx, fn, r.redis.Pipelined)
+}
+
+// TxPipelined runs the commands queued by fn in a MULTI/EXEC transaction as a
+// single breaker call, like Pipelined.
+func (r *ResilientCache) TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	return r.pipelined(ctx, fn, r.redis.TxPipelined)
+}
+
+func (r *ResilientCache) pipelined(ctx context.Context, fn func(redis.Pipeliner) error,
+	pipelined func(context.Context, func(redis.Pipeliner) error) ([]redis.Cmder, error),
+) ([]redis.Cmder, error) {
+	if !r.cfg.Enabled {
+		return nil, nil
+	}
+
+	var cmds []redis.Cmder
+	var cmdErr error
+	_, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+		cmds, cmdErr = pipelined(ctx, fn)
+		if errors.Is(cmdErr, redis.Nil) {
+			return nil, nil
+		}
+		return nil, cmdErr
+	})
+	if cmds == nil && err != nil {
+		return nil, err
+	}
+	return cmds, cmdErr
+}
+
+// invalidate publishes written or deleted keys when the cache has an
+// Invalidator, so other instances evict them from their local caches. Lock
+// keys are never cached locally, so they're left out.
+func (r *ResilientCache) invalidate(ctx context.Context, keys ...string) {
+	if r.opts.invalidator == nil {
+		return
+	}
+	keys = slices.DeleteFunc(slices.Clone(keys), func(key string) bool {
+		return strings.HasPrefix(key, lockPrefix)
+	})
+	if err := r.opts.invalidator.Publish(ctx, keys...); err != nil {
+		slog.WarnContext(ctx, "failed to invalidate cache keys", "err", err)
+	}
+}
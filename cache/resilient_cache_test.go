@@ -7,8 +7,8 @@ import (
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
-	"github.com/go-redis/redis/v8"
 	"github.com/marcw/cachecontrol"
+	"github.com/redis/go-redis/v9"
 	"github.com/sony/gobreaker"
 )
 
@@ -78,7 +78,7 @@ func TestRedisBreaker_Set(t *testing.T) {
 			rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
 			tt.redisSetup(rc)
 			r := NewRedisBreaker(tt.cfg, rc)
-			tt.breakerSetup(r.breaker)
+			tt.breakerSetup(r.breaker.CircuitBreaker)
 			value, err := r.Set(tt.ctx, "test-key", "test-value", time.Hour).Result()
 
 			if value != tt.wantValue {
@@ -158,7 +158,7 @@ func TestRedisBreaker_SetXX(t *testing.T) {
 			rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
 			tt.redisSetup(rc)
 			r := NewRedisBreaker(tt.cfg, rc)
-			tt.breakerSetup(r.breaker)
+			tt.breakerSetup(r.breaker.CircuitBreaker)
 			value, err := r.SetXX(tt.ctx, "test-key", "test-value", time.Hour).Result()
 
 			if value != tt.wantValue {
@@ -238,7 +238,7 @@ func TestRedisBreaker_SetNX(t *testing.T) {
 			rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
 			tt.redisSetup(rc)
 			r := NewRedisBreaker(tt.cfg, rc)
-			tt.breakerSetup(r.breaker)
+			tt.breakerSetup(r.breaker.CircuitBreaker)
 			value, err := r.SetNX(tt.ctx, "test-key", "test-value", time.Hour).Result()
 
 			if value != tt.wantValue {
@@ -330,7 +330,7 @@ func TestRedisBreaker_Get(t *testing.T) {
 			rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
 			tt.redisSetup(rc)
 			r := NewRedisBreaker(tt.cfg, rc)
-			tt.breakerSetup(r.breaker)
+			tt.breakerSetup(r.breaker.CircuitBreaker)
 			for i := 0; i < 10; i++ {
 				_, _ = r.Get(tt.ctx, "test-key").Result()
 			}
@@ -415,7 +415,7 @@ func TestRedisBreaker_Del(t *testing.T) {
 			rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
 			tt.redisSetup(rc)
 			r := NewRedisBreaker(tt.cfg, rc)
-			tt.breakerSetup(r.breaker)
+			tt.breakerSetup(r.breaker.CircuitBreaker)
 			value, err := r.Del(tt.ctx, "test-key").Result()
 
 			if value != tt.wantValue {
@@ -428,3 +428,62 @@ func TestRedisBreaker_Del(t *testing.T) {
 		})
 	}
 }
+
+func TestRedisBreaker_Commands(t *testing.T) {
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	r := NewRedisBreaker(Config{Enabled: true}, rc)
+	ctx := t.Context()
+	_ = rc.Set(ctx, "test-key", "test-value", 0)
+
+	values, err := r.MGet(ctx, "test-key", "test-missing").Result()
+	if err != nil || len(values) != 2 || values[0] != "test-value" || values[1] != nil {
+		t.Errorf("RedisBreaker.MGet() = %v, %v", values, err)
+	}
+	if n, err := r.Incr(ctx, "test-counter").Result(); err != nil || n != 1 {
+		t.Errorf("RedisBreaker.Incr() = %v, %v, want 1", n, err)
+	}
+	if ok, err := r.Expire(ctx, "test-key", time.Hour).Result(); err != nil || !ok {
+		t.Errorf("RedisBreaker.Expire() = %v, %v, want true", ok, err)
+	}
+	if ttl, err := r.TTL(ctx, "test-key").Result(); err != nil || ttl != time.Hour {
+		t.Errorf("RedisBreaker.TTL() = %v, %v, want 1h", ttl, err)
+	}
+
+	cmds, err := r.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Get(ctx, "test-key")
+		p.Get(ctx, "test-missing")
+		return nil
+	})
+	if !errors.Is(err, redis.Nil) || len(cmds) != 2 {
+		t.Errorf("RedisBreaker.Pipelined() = %v, %v, want 2 commands and redis.Nil", cmds, err)
+	}
+	if _, err := r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Incr(ctx, "test-counter")
+		return nil
+	}); err != nil {
+		t.Errorf("RedisBreaker.TxPipelined() error = %v", err)
+	}
+	if r.Breaker().Counts().TotalFailures != 0 {
+		t.Errorf("RedisBreaker failures = %d, want 0", r.Breaker().Counts().TotalFailures)
+	}
+
+	ctx = context.WithValue(ctx, CacheControlContextKey, cachecontrol.Parse("no-cache, no-store"))
+	if values, err := r.MGet(ctx, "test-key").Result(); err != nil || values[0] != nil {
+		t.Errorf("RedisBreaker.MGet() no-cache = %v, %v", values, err)
+	}
+	if n, err := r.Incr(ctx, "test-counter").Result(); err != nil || n != 0 {
+		t.Errorf("RedisBreaker.Incr() no-store = %v, %v, want 0", n, err)
+	}
+
+	_ = rc.Close()
+	if _, err := r.Pipelined(t.Context(), func(p redis.Pipeliner) error {
+		p.Get(t.Context(), "test-key")
+		return nil
+	}); err == nil {
+		t.Error("RedisBreaker.Pipelined() closed client error = nil")
+	}
+	if err := r.Healthy(t.Context()); err != nil {
+		t.Errorf("RedisBreaker.Healthy() error = %v", err)
+	}
+}
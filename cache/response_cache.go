@@ -0,0 +1,304 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResponseCache is HTTP middleware that caches the responses of GET and HEAD
+// handlers in a Backend. Responses are keyed by method, path, normalized query
+// and the request values of the Vary headers, and served with an ETag and
+// Last-Modified so conditional requests are answered with 304 Not Modified.
+// It respects the no-cache, no-store and max-age request cache control
+// settings. Requests with an Authorization header or cookies are only served
+// and stored responses marked public or s-maxage, like a shared HTTP cache.
+type ResponseCache struct {
+	name    string
+	cfg     Config
+	backend Backend
+	vary    []string
+}
+
+// ResponseCacheOption configures a ResponseCache.
+type ResponseCacheOption func(*ResponseCache)
+
+// WithVary sets the request headers whose values are part of the cache key,
+// such as Accept or Accept-Language. They're listed in the Vary response
+// header.
+func WithVary(headers ...string) ResponseCacheOption {
+	return func(c *ResponseCache) {
+		for _, h := range headers {
+			c.vary = append(c.vary, http.CanonicalHeaderKey(h))
+		}
+	}
+}
+
+// NewResponseCache creates a ResponseCache named name, which prefixes its keys,
+// storing responses in backend for the Config TTL.
+func NewResponseCache(name string, cfg Config, backend Backend, opts ...ResponseCacheOption) *ResponseCache {
+	c := &ResponseCache{
+		name:    name,
+		cfg:     cfg,
+		backend: backend,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// cachedResponse is a cached handler response and when it was stored.
+type cachedResponse struct {
+	Status int         `json:"s"`
+	Header http.Header `json:"h"`
+	Body   []byte      `json:"b"`
+	Stored time.Time   `json:"t"`
+}
+
+// Middleware caches the 200 OK responses of next. Responses that set a cookie
+// or a no-store or private Cache-Control aren't cached. Only responses that
+// can be cached are buffered; the rest, and responses the handler flushes,
+// are streamed.
+func (c *ResponseCache) Middleware(next http.Handler) http.Handler {
+	return ParseCacheControl(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.cfg.Enabled || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		key := c.key(r)
+		credentialed := r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+		if !NoCache(ctx) {
+			if resp, ok := c.get(ctx, key); ok && (!credentialed || shared(resp.Header)) {
+				w.Header().Set("Age", strconv.Itoa(int(time.Since(resp.Stored).Seconds())))
+				c.serve(w, r, resp)
+				return
+			}
+		}
+
+		rec := &responseRecorder{
+			w:      w,
+			vary:   c.vary,
+			header: http.Header{},
+			status: http.StatusOK,
+			store: func(status int, header http.Header) bool {
+				return r.Method == http.MethodGet && !NoStore(ctx) && cacheable(status, header, credentialed)
+			},
+		}
+		next.ServeHTTP(rec, r)
+		if !rec.wroteHeader {
+			rec.WriteHeader(http.StatusOK)
+		}
+		if !rec.buffering {
+			return
+		}
+
+		resp := cachedResponse{Status: rec.status, Header: rec.header, Body: rec.body.Bytes(), Stored: time.Now()}
+		if resp.Header.Get("ETag") == "" {
+			sum := sha256.Sum256(resp.Body)
+			resp.Header.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		}
+		if resp.Header.Get("Last-Modified") == "" {
+			resp.Header.Set("Last-Modified", resp.Stored.UTC().Format(http.TimeFormat))
+		}
+		if err := c.set(ctx, key, resp); err != nil {
+			slog.WarnContext(ctx, "failed to set cached response", "err", err, "key", key)
+		}
+		c.serve(w, r, resp)
+	}))
+}
+
+// key builds the cache key of a request. HEAD requests share the GET key, so
+// they're served from cached GET responses.
+func (c *ResponseCache) key(r *http.Request) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%s:%s", c.name, http.MethodGet, r.URL.EscapedPath())
+	if query := r.URL.Query().Encode(); query != "" {
+		b.WriteString("?" + query)
+	}
+	for _, h := range c.vary {
+		fmt.Fprintf(&b, "|%s=%s", h, strings.Join(r.Header.Values(h), ","))
+	}
+	return b.String()
+}
+
+func (c *ResponseCache) get(ctx context.Context, key string) (cachedResponse, bool) {
+	var resp cachedResponse
+	b, err := c.backend.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.WarnContext(ctx, "failed to get cached response", "err", err, "key", key)
+		}
+		return resp, false
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		slog.WarnContext(ctx, "failed to decode cached response", "err", err, "key", key)
+		return resp, false
+	}
+	if maxAge, ok := MaxAge(ctx); ok && time.Since(resp.Stored) > maxAge {
+		return resp, false
+	}
+	return resp, true
+}
+
+func (c *ResponseCache) set(ctx context.Context, key string, resp cachedResponse) error {
+	ttl := c.cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached response: %w", err)
+	}
+	return c.backend.Set(ctx, key, b, ttl)
+}
+
+// serve writes resp, or 304 Not Modified when the request's conditions match.
+func (c *ResponseCache) serve(w http.ResponseWriter, r *http.Request, resp cachedResponse) {
+	copyHeader(w, resp.Header, c.vary)
+	if resp.Status == http.StatusOK && notModified(r, resp.Header) {
+		for _, h := range []string{"Content-Type", "Content-Length"} {
+			w.Header().Del(h)
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if resp.Status == http.StatusOK {
+		w.Header().Set("Content-Length", strconv.Itoa(len(resp.Body)))
+	}
+	w.WriteHeader(resp.Status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(resp.Body)
+	}
+}
+
+// cacheable reports whether a response may be stored. Responses to requests
+// with credentials must also be shared.
+func cacheable(status int, header http.Header, credentialed bool) bool {
+	if status != http.StatusOK || header.Get("Set-Cookie") != "" {
+		return false
+	}
+	cc := strings.ToLower(header.Get("Cache-Control"))
+	if strings.Contains(cc, "no-store") || strings.Contains(cc, "private") {
+		return false
+	}
+	return !credentialed || shared(header)
+}
+
+// shared reports whether a response may be served to requests with
+// credentials, because it's marked public or s-maxage.
+func shared(header http.Header) bool {
+	cc := strings.ToLower(header.Get("Cache-Control"))
+	return strings.Contains(cc, "public") || strings.Contains(cc, "s-maxage")
+}
+
+// copyHeader copies header, and the Vary headers, into w.
+func copyHeader(w http.ResponseWriter, header http.Header, vary []string) {
+	for k, v := range header {
+		w.Header()[k] = v
+	}
+	for _, h := range vary {
+		w.Header().Add("Vary", h)
+	}
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since without it.
+func notModified(r *http.Request, header http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(header.Get("ETag"), "W/")
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || (etag != "" && strings.TrimPrefix(tag, "W/") == etag) {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(ims)
+}
+
+// responseRecorder buffers a handler's response when store reports it can be
+// cached once its header is written, and passes it through to w otherwise.
+// Without a store, it buffers every response and ignores flushes.
+type responseRecorder struct {
+	w           http.ResponseWriter
+	vary        []string
+	store       func(status int, header http.Header) bool
+	header      http.Header
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+	r.buffering = r.store == nil || r.store(status, r.header)
+	if !r.buffering {
+		copyHeader(r.w, r.header, r.vary)
+		r.w.WriteHeader(status)
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.buffering {
+		return r.body.Write(b)
+	}
+	return r.w.Write(b)
+}
+
+// Flush stops buffering, so a streamed response is written as it's flushed
+// and isn't cached.
+func (r *responseRecorder) Flush() {
+	if r.store == nil {
+		return
+	}
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.buffering {
+		r.buffering = false
+		copyHeader(r.w, r.header, r.vary)
+		r.w.WriteHeader(r.status)
+		_, _ = r.w.Write(r.body.Bytes())
+		r.body.Reset()
+	}
+	if f, ok := r.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.w
+}
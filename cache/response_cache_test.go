@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCache_Middleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		requests   []*http.Request
+		header     http.Header
+		status     int
+		wantStatus int
+		wantBody   string
+		wantCalls  int32
+	}{
+		{
+			name: "miss",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test?b=2&a=1", nil),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  1,
+		},
+		{
+			name: "hit with normalized query",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test?b=2&a=1", nil),
+				httptest.NewRequest(http.MethodGet, "/test?a=1&b=2", nil),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  1,
+		},
+		{
+			name: "different query",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test?a=1", nil),
+				httptest.NewRequest(http.MethodGet, "/test?a=2", nil),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "different vary header",
+			requests: []*http.Request{
+				request(http.MethodGet, "/test", http.Header{"Accept-Language": {"en"}}),
+				request(http.MethodGet, "/test", http.Header{"Accept-Language": {"fr"}}),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "head served from get",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				httptest.NewRequest(http.MethodHead, "/test", nil),
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name: "post not cached",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodPost, "/test", nil),
+				httptest.NewRequest(http.MethodPost, "/test", nil),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "error not cached",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+			},
+			status:     http.StatusInternalServerError,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "private not cached",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+			},
+			header:     http.Header{"Cache-Control": {"private"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "authorization not cached",
+			requests: []*http.Request{
+				request(http.MethodGet, "/test", http.Header{"Authorization": {"Bearer test-token"}}),
+				request(http.MethodGet, "/test", http.Header{"Authorization": {"Bearer test-token"}}),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "cookie not served anonymous response",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				request(http.MethodGet, "/test", http.Header{"Cookie": {"session=test-session"}}),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "authorization public cached",
+			requests: []*http.Request{
+				request(http.MethodGet, "/test", http.Header{"Authorization": {"Bearer test-token"}}),
+				request(http.MethodGet, "/test", http.Header{"Authorization": {"Bearer test-other"}}),
+			},
+			header:     http.Header{"Cache-Control": {"public, max-age=60"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  1,
+		},
+		{
+			name: "cache control: no cache",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				request(http.MethodGet, "/test", http.Header{"Cache-Control": {"no-cache"}}),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "cache control: no store",
+			requests: []*http.Request{
+				request(http.MethodGet, "/test", http.Header{"Cache-Control": {"no-store"}}),
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  2,
+		},
+		{
+			name: "if none match",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				request(http.MethodGet, "/test", http.Header{"If-None-Match": {`"test-etag"`}}),
+			},
+			header:     http.Header{"Etag": {`"test-etag"`}},
+			wantStatus: http.StatusNotModified,
+			wantCalls:  1,
+		},
+		{
+			name: "if none match changed",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				request(http.MethodGet, "/test", http.Header{"If-None-Match": {`"test-old"`}}),
+			},
+			header:     http.Header{"Etag": {`"test-etag"`}},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  1,
+		},
+		{
+			name: "if modified since",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				request(http.MethodGet, "/test", http.Header{"If-Modified-Since": {"Mon, 02 Jan 2006 15:04:05 GMT"}}),
+			},
+			header:     http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}},
+			wantStatus: http.StatusNotModified,
+			wantCalls:  1,
+		},
+		{
+			name: "modified since",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/test", nil),
+				request(http.MethodGet, "/test", http.Header{"If-Modified-Since": {"Sun, 01 Jan 2006 15:04:05 GMT"}}),
+			},
+			header:     http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "test-body",
+			wantCalls:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				_, _ = w.Write([]byte("test-body"))
+			})
+			cfg := Config{Enabled: true, TTL: time.Hour}
+			c := NewResponseCache("test", cfg, NewMemoryBackend(cfg), WithVary("Accept-Language"))
+			h := c.Middleware(handler)
+
+			var w *httptest.ResponseRecorder
+			for _, r := range tt.requests {
+				w = httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status got = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body got = %q, want %q", got, tt.wantBody)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler calls got = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResponseCache_Validators(t *testing.T) {
+	cfg := Config{Enabled: true, TTL: time.Hour}
+	h := NewResponseCache("test", cfg, NewMemoryBackend(cfg)).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("test-body"))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+	etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("validators got = %q, %q, want ETag and Last-Modified", etag, lastModified)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, request(http.MethodGet, "/test", http.Header{"If-None-Match": {etag}}))
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match status got = %d, want %d", w.Code, http.StatusNotModified)
+	}
+	if w.Header().Get("Age") == "" {
+		t.Error("Age header not set on cached response")
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, request(http.MethodGet, "/test", http.Header{"If-Modified-Since": {lastModified}}))
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since status got = %d, want %d", w.Code, http.StatusNotModified)
+	}
+}
+
+func TestResponseCache_Flush(t *testing.T) {
+	var calls atomic.Int32
+	cfg := Config{Enabled: true, TTL: time.Hour}
+	h := NewResponseCache("test", cfg, NewMemoryBackend(cfg)).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte("test-"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+		_, _ = w.Write([]byte("body"))
+	}))
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+		if !w.Flushed || w.Body.String() != "test-body" {
+			t.Errorf("response got = %q, flushed %v, want test-body flushed", w.Body.String(), w.Flushed)
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("handler calls got = %d, want 2", got)
+	}
+}
+
+func request(method, target string, header http.Header) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	r.Header = header
+	return r
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tagScript adds ARGV[1] to the tag set in KEYS[1] and keeps the set around
+// as long as its longest lived key, ARGV[2] milliseconds or forever when 0.
+const tagScript = `local new = redis.call("exists", KEYS[1]) == 0
+redis.call("sadd", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call("persist", KEYS[1])
+	return 1
+end
+local current = redis.call("pttl", KEYS[1])
+if new or (current >= 0 and current < ttl) then
+	redis.call("pexpire", KEYS[1], ttl)
+end
+return 1`
+
+// tagKey returns the key of the tag set, namespaced by the Config Namespace so
+// caches sharing a Redis don't share tags.
+func (r *ResilientCache) tagKey(tag string) string {
+	if r.cfg.Namespace == "" {
+		return "tag:" + tag
+	}
+	return r.cfg.Namespace + ":tag:" + tag
+}
+
+// SetWithTags sets key like Set and records it as a member of each tag, so it's
+// deleted by InvalidateTag.
+func (r *ResilientCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) *redis.StatusCmd {
+	if NoStore(ctx) || !r.cfg.Enabled {
+		return redis.NewStatusResult("", nil)
+	}
+
+	var set *redis.StatusCmd
+	_, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+		_, err := r.redis.Pipelined(ctx, func(p redis.Pipeliner) error {
+			set = p.Set(ctx, key, value, ttl)
+			r.tag(ctx, p, key, ttl, tags)
+			return nil
+		})
+		return nil, err
+	})
+	if err != nil {
+		return redis.NewStatusResult("", err)
+	}
+	r.invalidate(ctx, key)
+	return set
+}
+
+// Tag records key, set with ttl, as a member of each tag. Use it for keys set
+// through the go-redis/cache, which has no tags.
+func (r *ResilientCache) Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	if NoStore(ctx) || !r.cfg.Enabled || len(tags) == 0 {
+		return nil
+	}
+
+	_, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+		_, err := r.redis.Pipelined(ctx, func(p redis.Pipeliner) error {
+			r.tag(ctx, p, key, ttl, tags)
+			return nil
+		})
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tag cache key: %w", err)
+	}
+	return nil
+}
+
+// Each tag is updated by its own script so tags can live on different
+// cluster slots.
+func (r *ResilientCache) tag(ctx context.Context, p redis.Pipeliner, key string, ttl time.Duration, tags []string) {
+	for _, tag := range tags {
+		p.Eval(ctx, tagScript, []string{r.tagKey(tag)}, key, ttl.Milliseconds())
+	}
+}
+
+// InvalidateTag deletes every key tagged with tag, in batches, from Redis and
+// from the local cache, and publishes them to the Invalidator. Each batch is a
+// breaker call, so an open breaker fails it fast; keys of batches that weren't
+// deleted stay tagged and are deleted by the next InvalidateTag.
+func (r *ResilientCache) InvalidateTag(ctx context.Context, tag string) error {
+	if !r.cfg.Enabled {
+		return nil
+	}
+
+	for {
+		// SPOP removes the batch from the tag set, so keys tagged while
+		// invalidating are picked up by a later batch and the set is removed
+		// once it's empty.
+		result, err := r.breaker.Execute(ctx, func() (interface{}, error) {
+			keys, err := r.redis.SPopN(ctx, r.tagKey(tag), r.opts.tagBatchSize).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return nil, err
+			}
+			if len(keys) == 0 {
+				return keys, nil
+			}
+			_, err = r.redis.Pipelined(ctx, func(p redis.Pipeliner) error {
+				// Deleting keys one by one keeps each command on a single
+				// cluster slot.
+				for _, key := range keys {
+					p.Del(ctx, key)
+				}
+				return nil
+			})
+			if err != nil {
+				// Put the batch back so it isn't lost.
+				members := make([]interface{}, len(keys))
+				for i, key := range keys {
+					members[i] = key
+				}
+				r.redis.SAdd(context.WithoutCancel(ctx), r.tagKey(tag), members...)
+				return nil, err
+			}
+			return keys, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to invalidate cache tag: %w", err)
+		}
+
+		keys, _ := result.([]string)
+		if len(keys) == 0 {
+			return nil
+		}
+		if r.local != nil {
+			_ = r.local.Delete(ctx, keys...)
+		}
+		r.invalidate(ctx, keys...)
+	}
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/cache/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisBreaker_SetWithTags(t *testing.T) {
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	r := NewRedisBreaker(Config{Enabled: true}, rc)
+	ctx := t.Context()
+
+	if err := r.SetWithTags(ctx, "test-key-1", "test-value", time.Minute, "test-tag", "test-other").Err(); err != nil {
+		t.Fatalf("RedisBreaker.SetWithTags() error = %v", err)
+	}
+	if err := r.SetWithTags(ctx, "test-key-2", "test-value", time.Hour, "test-tag").Err(); err != nil {
+		t.Fatalf("RedisBreaker.SetWithTags() error = %v", err)
+	}
+	if err := r.SetWithTags(ctx, "test-key-3", "test-value", time.Second, "test-tag").Err(); err != nil {
+		t.Fatalf("RedisBreaker.SetWithTags() error = %v", err)
+	}
+
+	if got, _ := s.Get("test-key-1"); got != "test-value" {
+		t.Errorf("test-key-1 = %q, want test-value", got)
+	}
+	members, _ := s.Members("tag:test-tag")
+	if len(members) != 3 {
+		t.Errorf("tag:test-tag members = %v, want 3 keys", members)
+	}
+	// The tag lives as long as its longest lived key.
+	if ttl := s.TTL("tag:test-tag"); ttl != time.Hour {
+		t.Errorf("tag:test-tag TTL = %v, want 1h", ttl)
+	}
+	if ttl := s.TTL("tag:test-other"); ttl != time.Minute {
+		t.Errorf("tag:test-other TTL = %v, want 1m", ttl)
+	}
+
+	if err := r.Tag(ctx, "test-key-4", 0, "test-tag"); err != nil {
+		t.Fatalf("RedisBreaker.Tag() error = %v", err)
+	}
+	if ttl := s.TTL("tag:test-tag"); ttl != 0 {
+		t.Errorf("tag:test-tag TTL = %v, want no expiry", ttl)
+	}
+}
+
+func TestRedisBreaker_InvalidateTag(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          Config
+		breakerSetup func(*Breaker)
+		wantDeleted  bool
+		wantErr      bool
+	}{
+		{
+			name:         "invalidate",
+			cfg:          Config{Enabled: true},
+			breakerSetup: func(b *Breaker) {},
+			wantDeleted:  true,
+		},
+		{
+			name: "breaker open",
+			cfg:  Config{Enabled: true},
+			breakerSetup: func(b *Breaker) {
+				for i := 0; i < 10; i++ {
+					_, _ = b.CircuitBreaker.Execute(func() (interface{}, error) { return nil, errors.New("test-error") })
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:         "disabled",
+			cfg:          Config{Enabled: false},
+			breakerSetup: func(b *Breaker) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := miniredis.RunT(t)
+			rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+			r := NewRedisBreaker(tt.cfg, rc, WithTagBatchSize(2))
+			ctx := t.Context()
+			for i := range 5 {
+				key := fmt.Sprintf("test-key-%d", i)
+				_ = s.Set(key, "test-value")
+				_, _ = s.SAdd("tag:test-tag", key)
+			}
+			_ = s.Set("test-untagged", "test-value")
+			tt.breakerSetup(r.Breaker())
+
+			err := r.InvalidateTag(ctx, "test-tag")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RedisBreaker.InvalidateTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for i := range 5 {
+				if deleted := !s.Exists(fmt.Sprintf("test-key-%d", i)); deleted != tt.wantDeleted {
+					t.Errorf("test-key-%d deleted = %v, want %v", i, deleted, tt.wantDeleted)
+				}
+			}
+			if tt.wantDeleted && s.Exists("tag:test-tag") {
+				t.Error("tag:test-tag exists after invalidation")
+			}
+			if !s.Exists("test-untagged") {
+				t.Error("test-untagged deleted")
+			}
+		})
+	}
+}
+
+func TestNewCacheWithBreaker_InvalidateTag(t *testing.T) {
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	cfg := Config{Enabled: true, Size: 100, LocalTTL: time.Minute}
+	r := NewRedisBreaker(cfg, rc)
+	c := NewCacheWithBreaker(cfg, r)
+	ctx := t.Context()
+
+	if err := c.Set(&cache.Item{Ctx: ctx, Key: "test-key", Value: "test-value", TTL: time.Hour}); err != nil {
+		t.Fatalf("Cache.Set() error = %v", err)
+	}
+	if err := r.Tag(ctx, "test-key", time.Hour, "test-tag"); err != nil {
+		t.Fatalf("RedisBreaker.Tag() error = %v", err)
+	}
+	if err := r.InvalidateTag(ctx, "test-tag"); err != nil {
+		t.Fatalf("RedisBreaker.InvalidateTag() error = %v", err)
+	}
+
+	var value string
+	if err := c.Get(ctx, "test-key", &value); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("Cache.Get() = %q, %v, want cache miss", value, err)
+	}
+}
+
+func TestRedisBreaker_TagNamespace(t *testing.T) {
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	users := NewRedisBreaker(Config{Enabled: true, Namespace: "test-users"}, rc)
+	orders := NewRedisBreaker(Config{Enabled: true, Namespace: "test-orders"}, rc)
+	ctx := t.Context()
+
+	if err := users.SetWithTags(ctx, "test-user", "test-value", time.Minute, "test-tag").Err(); err != nil {
+		t.Fatalf("RedisBreaker.SetWithTags() error = %v", err)
+	}
+	if err := orders.SetWithTags(ctx, "test-order", "test-value", time.Minute, "test-tag").Err(); err != nil {
+		t.Fatalf("RedisBreaker.SetWithTags() error = %v", err)
+	}
+	if members, _ := s.Members("test-users:tag:test-tag"); len(members) != 1 {
+		t.Errorf("test-users:tag:test-tag members = %v, want test-user", members)
+	}
+
+	if err := users.InvalidateTag(ctx, "test-tag"); err != nil {
+		t.Fatalf("RedisBreaker.InvalidateTag() error = %v", err)
+	}
+	if s.Exists("test-user") {
+		t.Error("test-user exists after invalidation")
+	}
+	if !s.Exists("test-order") {
+		t.Error("test-order deleted by another namespace's tag")
+	}
+}
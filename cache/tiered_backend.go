@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// PromotionRule decides whether a value read from the remote tier of a
+// TieredBackend is copied into the local tier.
+type PromotionRule func(ctx context.Context, key string, value []byte) bool
+
+// PromoteAlways promotes every value read from the remote tier.
+func PromoteAlways(context.Context, string, []byte) bool { return true }
+
+// PromoteNever only caches values locally when they are set through the
+// TieredBackend.
+func PromoteNever(context.Context, string, []byte) bool { return false }
+
+// PromoteMaxSize promotes values of up to size bytes, keeping large values out
+// of memory.
+func PromoteMaxSize(size int) PromotionRule {
+	return func(_ context.Context, _ string, value []byte) bool {
+		return len(value) <= size
+	}
+}
+
+// TieredBackend layers a local backend (L1), usually a MemoryBackend, over a
+// remote backend (L2), usually a RedisBackend. Reads try L1, then L2, and
+// promote L2 hits into L1 as the promotion rule allows. Writes and deletes go
+// to L2, then L1. Local entries live for up to Config.LocalTTL, so other
+// instances' writes are seen within that time, and never outlive the L2 entry
+// when L2 is a TTLBackend.
+type TieredBackend struct {
+	cfg     Config
+	local   Backend
+	remote  Backend
+	promote PromotionRule
+}
+
+// TieredOption configures a TieredBackend.
+type TieredOption func(*TieredBackend)
+
+// WithPromotionRule sets the rule for promoting remote values into the local
+// tier, PromoteAlways by default.
+func WithPromotionRule(rule PromotionRule) TieredOption {
+	return func(t *TieredBackend) {
+		t.promote = rule
+	}
+}
+
+// NewTieredBackend creates a two tier backend of local over remote.
+func NewTieredBackend(cfg Config, local, remote Backend, opts ...TieredOption) *TieredBackend {
+	t := &TieredBackend{
+		cfg:     cfg,
+		local:   local,
+		remote:  remote,
+		promote: PromoteAlways,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *TieredBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := t.local.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		slog.WarnContext(ctx, "failed to get local cache entry", "err", err, "key", key)
+	}
+
+	value, ttl, err := t.getRemote(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if t.promote(ctx, key, value) {
+		if err := t.local.Set(ctx, key, value, t.localTTL(ttl)); err != nil {
+			slog.WarnContext(ctx, "failed to promote cache entry", "err", err, "key", key)
+		}
+	}
+	return value, nil
+}
+
+func (t *TieredBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := t.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return t.local.Set(ctx, key, value, t.localTTL(ttl))
+}
+
+func (t *TieredBackend) Delete(ctx context.Context, keys ...string) error {
+	return errors.Join(t.remote.Delete(ctx, keys...), t.local.Delete(ctx, keys...))
+}
+
+// getRemote gets key from L2 along with its remaining time to live, or 0 when
+// L2 can't report it.
+func (t *TieredBackend) getRemote(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	if remote, ok := t.remote.(TTLBackend); ok {
+		return remote.GetWithTTL(ctx, key)
+	}
+	value, err := t.remote.Get(ctx, key)
+	return value, 0, err
+}
+
+// localTTL caps ttl at Config.LocalTTL.
+func (t *TieredBackend) localTTL(ttl time.Duration) time.Duration {
+	if t.cfg.LocalTTL > 0 && (ttl <= 0 || ttl > t.cfg.LocalTTL) {
+		return t.cfg.LocalTTL
+	}
+	return ttl
+}
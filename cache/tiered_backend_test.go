@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTieredBackend_Get(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        PromotionRule
+		value       string
+		wantPromote bool
+	}{
+		{
+			name:        "promote always",
+			rule:        PromoteAlways,
+			value:       "test-value",
+			wantPromote: true,
+		},
+		{
+			name:  "promote never",
+			rule:  PromoteNever,
+			value: "test-value",
+		},
+		{
+			name:        "promote small value",
+			rule:        PromoteMaxSize(10),
+			value:       "test-value",
+			wantPromote: true,
+		},
+		{
+			name:  "skip large value",
+			rule:  PromoteMaxSize(10),
+			value: "test-large-value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Enabled: true, LocalTTL: time.Minute}
+			local, remote := NewMemoryBackend(cfg), NewMemoryBackend(cfg)
+			tiered := NewTieredBackend(cfg, local, remote, WithPromotionRule(tt.rule))
+			_ = remote.Set(t.Context(), "test-key", []byte(tt.value), time.Hour)
+
+			got, err := tiered.Get(t.Context(), "test-key")
+			if err != nil || string(got) != tt.value {
+				t.Fatalf("TieredBackend.Get() = %s, %v, want %s", got, err, tt.value)
+			}
+			_, err = local.Get(t.Context(), "test-key")
+			if promoted := err == nil; promoted != tt.wantPromote {
+				t.Errorf("TieredBackend.Get() promoted = %v, want %v", promoted, tt.wantPromote)
+			}
+		})
+	}
+}
+
+func TestTieredBackend_PromoteTTL(t *testing.T) {
+	cfg := Config{Enabled: true, LocalTTL: time.Minute}
+	local, remote := NewMemoryBackend(cfg), NewMemoryBackend(cfg)
+	now := time.Now()
+	local.now = func() time.Time { return now }
+	remote.now = func() time.Time { return now }
+	tiered := NewTieredBackend(cfg, local, remote)
+	_ = remote.Set(t.Context(), "test-key", []byte("test-value"), 30*time.Second)
+
+	if _, err := tiered.Get(t.Context(), "test-key"); err != nil {
+		t.Fatalf("TieredBackend.Get() error = %v", err)
+	}
+	_, ttl, err := local.GetWithTTL(t.Context(), "test-key")
+	if err != nil || ttl != 30*time.Second {
+		t.Errorf("local.GetWithTTL() ttl = %v, %v, want %v", ttl, err, 30*time.Second)
+	}
+}
+
+func TestTieredBackend_SetDelete(t *testing.T) {
+	cfg := Config{Enabled: true, LocalTTL: time.Minute}
+	local, remote := NewMemoryBackend(cfg), NewMemoryBackend(cfg)
+	now := time.Now()
+	local.now = func() time.Time { return now }
+	tiered := NewTieredBackend(cfg, local, remote)
+
+	if err := tiered.Set(t.Context(), "test-key", []byte("test-value"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Get(t.Context(), "test-key"); err != nil {
+		t.Errorf("remote.Get() error = %v", err)
+	}
+	now = now.Add(time.Minute)
+	if _, err := local.Get(t.Context(), "test-key"); err == nil {
+		t.Error("local.Get() error = nil, want local entry expired after LocalTTL")
+	}
+
+	if err := tiered.Delete(t.Context(), "test-key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tiered.Get(t.Context(), "test-key"); err == nil {
+		t.Error("TieredBackend.Get() error = nil, want ErrNotFound after Delete")
+	}
+}
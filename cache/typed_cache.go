@@ -0,0 +1,295 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultTTL matches the go-redis/cache TTL for items without one.
+const defaultTTL = time.Hour
+
+// Loader loads the value for a key on a cache miss.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// Cache is a typed read-through cache over a Backend. Concurrent misses for a
+// key are collapsed into a single load, and entries are refreshed early with
+// probabilistic early expiration (XFetch) so they don't all expire at once
+// under load. It respects the no-cache, no-store, max-age, max-stale,
+// stale-while-revalidate and stale-if-error cache control settings; entries
+// are kept for Config.StaleTTL past their expiry to be served stale. Values
+// are encoded with the WithCodec codec and compressed per WithCompression.
+type Cache[K comparable, V any] struct {
+	name    string
+	cfg     Config
+	backend Backend
+	opts    options
+	codec   serializer
+	group   singleflight.Group
+
+	attrs        metric.MeasurementOption
+	hits         metric.Int64Counter
+	misses       metric.Int64Counter
+	loadDuration metric.Float64Histogram
+}
+
+// entry is a cached value with the time it took to load, when it was written
+// and when it expires, used to decide when to refresh it and whether it may be
+// served. The JSON tags decode entries written before codecs.
+type entry[V any] struct {
+	Value   V             `json:"v"`
+	Delta   time.Duration `json:"d"`
+	Written time.Time     `json:"w"`
+	Expiry  time.Time     `json:"e"`
+}
+
+// New creates a Cache named name, which prefixes its keys and labels its
+// metrics, storing values in backend for the Config TTL.
+func New[K comparable, V any](name string, cfg Config, backend Backend, opts ...Option) *Cache[K, V] {
+	o := newOptions(opts)
+
+	c := &Cache[K, V]{
+		name:    name,
+		cfg:     cfg,
+		backend: backend,
+		opts:    o,
+		codec:   newSerializer(o),
+		attrs:   metric.WithAttributeSet(attribute.NewSet(attribute.String("cache.name", name))),
+	}
+	meter := o.meterProvider.Meter("github.com/jesse0michael/pkg/cache")
+	var err error
+	if c.hits, err = meter.Int64Counter("cache.hits", metric.WithDescription("Number of cache hits")); err != nil {
+		otel.Handle(err)
+	}
+	if c.misses, err = meter.Int64Counter("cache.misses", metric.WithDescription("Number of cache misses")); err != nil {
+		otel.Handle(err)
+	}
+	if c.loadDuration, err = meter.Float64Histogram("cache.load.duration",
+		metric.WithDescription("Duration of cache loads"), metric.WithUnit("s")); err != nil {
+		otel.Handle(err)
+	}
+	return c
+}
+
+// GetOrLoad returns the cached value for key, or loads it with loader and
+// caches it. When the cached value is due for an early refresh and the load
+// fails, the cached value is returned. An expired value is returned within the
+// max-stale window, returned and refreshed in the background within the
+// stale-while-revalidate window, and returned when the load fails within the
+// stale-if-error window.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error) {
+	k := c.key(key)
+	if !c.cfg.Enabled || NoCache(ctx) {
+		c.misses.Add(ctx, 1, c.attrs)
+		return c.load(ctx, key, k, loader)
+	}
+
+	e, ok := c.get(ctx, k)
+	if ok {
+		if maxAge, set := MaxAge(ctx); set && !e.Written.IsZero() && time.Since(e.Written) > maxAge {
+			ok = false
+		}
+	}
+	if !ok {
+		c.misses.Add(ctx, 1, c.attrs)
+		return c.load(ctx, key, k, loader)
+	}
+
+	stale := time.Since(e.Expiry)
+	if e.Expiry.IsZero() || stale < 0 {
+		c.hits.Add(ctx, 1, c.attrs)
+		if !c.expiresEarly(e) {
+			return e.Value, nil
+		}
+		if v, err := c.load(ctx, key, k, loader); err == nil {
+			return v, nil
+		}
+		return e.Value, nil
+	}
+	if maxStale, set := MaxStale(ctx); set && stale <= maxStale {
+		c.hits.Add(ctx, 1, c.attrs)
+		return e.Value, nil
+	}
+	if swr, set := StaleWhileRevalidate(ctx); set && stale <= swr {
+		c.hits.Add(ctx, 1, c.attrs)
+		go func() {
+			if _, err := c.load(context.WithoutCancel(ctx), key, k, loader); err != nil {
+				slog.WarnContext(ctx, "failed to revalidate cache entry", "err", err, "key", k)
+			}
+		}()
+		return e.Value, nil
+	}
+
+	c.misses.Add(ctx, 1, c.attrs)
+	v, err := c.load(ctx, key, k, loader)
+	if err != nil {
+		if sie, set := StaleIfError(ctx); set && stale <= sie {
+			slog.WarnContext(ctx, "failed to load cache entry, serving stale", "err", err, "key", k)
+			return e.Value, nil
+		}
+	}
+	return v, err
+}
+
+// Set caches value for key, unless the context's cache control is no-store.
+func (c *Cache[K, V]) Set(ctx context.Context, key K, value V) error {
+	if NoStore(ctx) {
+		return nil
+	}
+	return c.set(ctx, c.key(key), entry[V]{Value: value})
+}
+
+// Delete removes key from the cache.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) error {
+	return c.backend.Delete(ctx, c.key(key))
+}
+
+func (c *Cache[K, V]) key(key K) string {
+	return fmt.Sprintf("%s:%v", c.name, key)
+}
+
+func (c *Cache[K, V]) get(ctx context.Context, key string) (entry[V], bool) {
+	var e entry[V]
+	b, err := c.backend.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.WarnContext(ctx, "failed to get cache entry", "err", err, "key", key)
+		}
+		return e, false
+	}
+	if err := c.decode(b, &e); err != nil {
+		slog.WarnContext(ctx, "failed to decode cache entry", "err", err, "key", key)
+		return e, false
+	}
+	return e, true
+}
+
+func (c *Cache[K, V]) set(ctx context.Context, key string, e entry[V]) error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	ttl := c.cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	e.Written = time.Now()
+	e.Expiry = e.Written.Add(ttl)
+	b, err := c.encode(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	// Keep the entry past its expiry so it can be served stale.
+	return c.backend.Set(ctx, key, b, ttl+max(c.cfg.StaleTTL, 0))
+}
+
+// entryHeader is the length of an encoded entry's version byte, delta,
+// written and expiry times, which precede its encoded value.
+const entryHeader = 1 + 3*8
+
+func (c *Cache[K, V]) encode(e entry[V]) ([]byte, error) {
+	version, body, err := c.codec.marshal(e.Value)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, entryHeader, entryHeader+len(body))
+	b[0] = version
+	binary.BigEndian.PutUint64(b[1:], uint64(e.Delta))
+	binary.BigEndian.PutUint64(b[9:], uint64(unixNano(e.Written)))
+	binary.BigEndian.PutUint64(b[17:], uint64(unixNano(e.Expiry)))
+	return append(b, body...), nil
+}
+
+// decode reads entries written by encode, and JSON entries written before
+// codecs.
+func (c *Cache[K, V]) decode(b []byte, e *entry[V]) error {
+	if len(b) > 0 && b[0] == '{' {
+		return json.Unmarshal(b, e)
+	}
+	if len(b) < entryHeader {
+		return errors.New("failed to decode cache entry: too short")
+	}
+	e.Delta = time.Duration(binary.BigEndian.Uint64(b[1:]))
+	e.Written = fromUnixNano(int64(binary.BigEndian.Uint64(b[9:])))
+	e.Expiry = fromUnixNano(int64(binary.BigEndian.Uint64(b[17:])))
+	return c.codec.unmarshal(b[0], b[entryHeader:], &e.Value)
+}
+
+// unixNano returns 0 for the zero time, which has no Unix nanoseconds.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// load calls loader once for concurrent callers of the same key and caches
+// the value it returns. The shared load is detached from the caller's
+// cancellation and stores regardless of the caller's cache control, since
+// other callers wait on it; a no-store caller loads on its own instead.
+func (c *Cache[K, V]) load(ctx context.Context, key K, k string, loader Loader[K, V]) (V, error) {
+	if NoStore(ctx) {
+		v, _, err := c.call(ctx, key, loader)
+		return v, err
+	}
+
+	ch := c.group.DoChan(k, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+		v, delta, err := c.call(ctx, key, loader)
+		if err != nil {
+			return v, err
+		}
+		if err := c.set(ctx, k, entry[V]{Value: v, Delta: delta}); err != nil {
+			slog.WarnContext(ctx, "failed to set cache entry", "err", err, "key", k)
+		}
+		return v, nil
+	})
+
+	select {
+	case res := <-ch:
+		v, _ := res.Val.(V)
+		return v, res.Err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// call calls loader and records how long it took.
+func (c *Cache[K, V]) call(ctx context.Context, key K, loader Loader[K, V]) (V, time.Duration, error) {
+	start := time.Now()
+	v, err := loader(ctx, key)
+	delta := time.Since(start)
+	c.loadDuration.Record(ctx, delta.Seconds(), c.attrs)
+	return v, delta, err
+}
+
+// expiresEarly reports whether an entry should be refreshed before it
+// expires, with a probability that grows as it nears expiry and with the time
+// it took to load.
+func (c *Cache[K, V]) expiresEarly(e entry[V]) bool {
+	if c.opts.beta <= 0 || e.Expiry.IsZero() {
+		return false
+	}
+	// -log(r) for r in (0, 1] is exponentially distributed, so refreshes are
+	// rare far from expiry and near certain close to it.
+	early := time.Duration(float64(e.Delta) * c.opts.beta * -math.Log(1-rand.Float64()))
+	return !time.Now().Add(early).Before(e.Expiry)
+}
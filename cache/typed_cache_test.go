@@ -0,0 +1,364 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/marcw/cachecontrol"
+	"github.com/redis/go-redis/v9"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func newTestBackend(t *testing.T) Backend {
+	t.Helper()
+	s := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	return RedisCacheBackend(NewCache(Config{Enabled: true, Size: 100, LocalTTL: time.Minute}, rc))
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		cfg       Config
+		cached    bool
+		loadErr   error
+		want      string
+		wantLoads int
+		wantErr   bool
+	}{
+		{
+			name:      "miss",
+			ctx:       t.Context(),
+			cfg:       Config{Enabled: true, TTL: time.Hour},
+			want:      "test-loaded",
+			wantLoads: 1,
+		},
+		{
+			name:   "hit",
+			ctx:    t.Context(),
+			cfg:    Config{Enabled: true, TTL: time.Hour},
+			cached: true,
+			want:   "test-cached",
+		},
+		{
+			name:      "cache control: no cache",
+			ctx:       context.WithValue(t.Context(), CacheControlContextKey, cachecontrol.Parse("no-cache")),
+			cfg:       Config{Enabled: true, TTL: time.Hour},
+			cached:    true,
+			want:      "test-loaded",
+			wantLoads: 1,
+		},
+		{
+			name:      "disabled",
+			ctx:       t.Context(),
+			cfg:       Config{Enabled: false},
+			cached:    true,
+			want:      "test-loaded",
+			wantLoads: 1,
+		},
+		{
+			name:      "load error",
+			ctx:       t.Context(),
+			cfg:       Config{Enabled: true, TTL: time.Hour},
+			loadErr:   errors.New("test-error"),
+			wantLoads: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend(t)
+			if tt.cached {
+				_ = New[string, string]("test", Config{Enabled: true, TTL: time.Hour}, backend).
+					Set(t.Context(), "test-key", "test-cached")
+			}
+			c := New[string, string]("test", tt.cfg, backend)
+
+			var loads int
+			got, err := c.GetOrLoad(tt.ctx, "test-key", func(ctx context.Context, key string) (string, error) {
+				loads++
+				return "test-loaded", tt.loadErr
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOrLoad() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("GetOrLoad() = %v, want %v", got, tt.want)
+			}
+			if loads != tt.wantLoads {
+				t.Errorf("GetOrLoad() loads = %d, want %d", loads, tt.wantLoads)
+			}
+		})
+	}
+}
+
+func TestCache_GetOrLoad_NoStore(t *testing.T) {
+	backend := newTestBackend(t)
+	c := New[string, string]("test", Config{Enabled: true, TTL: time.Hour}, backend)
+	ctx := context.WithValue(t.Context(), CacheControlContextKey, cachecontrol.Parse("no-store"))
+	loader := func(ctx context.Context, key string) (string, error) { return "test-loaded", nil }
+
+	if _, err := c.GetOrLoad(ctx, "test-key", loader); err != nil {
+		t.Fatalf("GetOrLoad() error = %v", err)
+	}
+	if _, err := backend.Get(t.Context(), "test:test-key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Backend.Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCache_GetOrLoad_Singleflight(t *testing.T) {
+	c := New[int, int]("test", Config{Enabled: true, TTL: time.Hour}, newTestBackend(t))
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.GetOrLoad(t.Context(), 1, func(ctx context.Context, key int) (int, error) {
+				loads.Add(1)
+				<-release
+				return key * 10, nil
+			})
+			if err != nil || got != 10 {
+				t.Errorf("GetOrLoad() = %v, %v, want 10", got, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := loads.Load(); got != 1 {
+		t.Errorf("GetOrLoad() loads = %d, want 1", got)
+	}
+}
+
+func TestCache_GetOrLoad_SingleflightCanceled(t *testing.T) {
+	backend := newTestBackend(t)
+	c := New[string, string]("test", Config{Enabled: true, TTL: time.Hour}, backend)
+
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (string, error) {
+		<-release
+		return "test-loaded", ctx.Err()
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	leader := make(chan error)
+	go func() {
+		_, err := c.GetOrLoad(ctx, "test-key", loader)
+		leader <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	follower := make(chan string)
+	go func() {
+		v, err := c.GetOrLoad(t.Context(), "test-key", loader)
+		if err != nil {
+			t.Errorf("GetOrLoad() error = %v", err)
+		}
+		follower <- v
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("GetOrLoad() leader error = %v, want context.Canceled", err)
+	}
+	close(release)
+
+	if got := <-follower; got != "test-loaded" {
+		t.Errorf("GetOrLoad() follower = %q, want test-loaded", got)
+	}
+	if _, err := backend.Get(t.Context(), "test:test-key"); err != nil {
+		t.Errorf("Backend.Get() error = %v", err)
+	}
+}
+
+func TestCache_GetOrLoad_EarlyExpiration(t *testing.T) {
+	backend := newTestBackend(t)
+	cfg := Config{Enabled: true, TTL: time.Hour}
+	loader := func(value string, err error) Loader[string, string] {
+		return func(ctx context.Context, key string) (string, error) {
+			time.Sleep(time.Millisecond)
+			return value, err
+		}
+	}
+	if _, err := New[string, string]("test", cfg, backend).GetOrLoad(t.Context(), "test-key", loader("test-first", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A huge beta makes the entry due for refresh regardless of its TTL.
+	c := New[string, string]("test", cfg, backend, WithBeta(1e9))
+	got, err := c.GetOrLoad(t.Context(), "test-key", loader("", errors.New("test-error")))
+	if err != nil || got != "test-first" {
+		t.Errorf("GetOrLoad() = %v, %v, want stale value on refresh error", got, err)
+	}
+	got, err = c.GetOrLoad(t.Context(), "test-key", loader("test-second", nil))
+	if err != nil || got != "test-second" {
+		t.Errorf("GetOrLoad() = %v, %v, want refreshed value", got, err)
+	}
+}
+
+func TestCache_GetOrLoad_CacheControl(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		written   time.Duration
+		expired   time.Duration
+		loadErr   error
+		want      string
+		wantLoads int32
+		wantErr   bool
+	}{
+		{
+			name:    "fresh",
+			written: -time.Minute,
+			expired: -time.Hour,
+			want:    "test-cached",
+		},
+		{
+			name:      "max-age exceeded",
+			header:    "max-age=30",
+			written:   time.Minute,
+			expired:   -time.Hour,
+			want:      "test-loaded",
+			wantLoads: 1,
+		},
+		{
+			name:      "expired",
+			written:   2 * time.Hour,
+			expired:   time.Hour,
+			want:      "test-loaded",
+			wantLoads: 1,
+		},
+		{
+			name:    "max-stale",
+			header:  "max-stale=7200",
+			written: 2 * time.Hour,
+			expired: time.Hour,
+			want:    "test-cached",
+		},
+		{
+			name:      "max-stale exceeded",
+			header:    "max-stale=60",
+			written:   2 * time.Hour,
+			expired:   time.Hour,
+			want:      "test-loaded",
+			wantLoads: 1,
+		},
+		{
+			name:      "stale-while-revalidate",
+			header:    "stale-while-revalidate=7200",
+			written:   2 * time.Hour,
+			expired:   time.Hour,
+			want:      "test-cached",
+			wantLoads: 1,
+		},
+		{
+			name:      "stale-if-error",
+			header:    "stale-if-error=7200",
+			written:   2 * time.Hour,
+			expired:   time.Hour,
+			loadErr:   errors.New("test-error"),
+			want:      "test-cached",
+			wantLoads: 1,
+		},
+		{
+			name:      "stale-if-error exceeded",
+			header:    "stale-if-error=60",
+			written:   2 * time.Hour,
+			expired:   time.Hour,
+			loadErr:   errors.New("test-error"),
+			wantLoads: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Enabled: true, TTL: time.Hour, StaleTTL: 24 * time.Hour}
+			backend := NewMemoryBackend(cfg)
+			b, _ := json.Marshal(entry[string]{
+				Value:   "test-cached",
+				Written: time.Now().Add(-tt.written),
+				Expiry:  time.Now().Add(-tt.expired),
+			})
+			_ = backend.Set(t.Context(), "test:test-key", b, 0)
+
+			ctx := t.Context()
+			if tt.header != "" {
+				ctx = context.WithValue(ctx, CacheControlContextKey, cachecontrol.Parse(tt.header))
+			}
+			var loads atomic.Int32
+			loaded := make(chan struct{}, 1)
+			c := New[string, string]("test", cfg, backend)
+			got, err := c.GetOrLoad(ctx, "test-key", func(ctx context.Context, key string) (string, error) {
+				loads.Add(1)
+				loaded <- struct{}{}
+				if tt.loadErr != nil {
+					return "", tt.loadErr
+				}
+				return "test-loaded", nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetOrLoad() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetOrLoad() got = %v, want %v", got, tt.want)
+			}
+			if tt.wantLoads > 0 {
+				select {
+				case <-loaded:
+				case <-time.After(time.Second):
+					t.Fatal("GetOrLoad() did not load")
+				}
+			}
+			if got := loads.Load(); got != tt.wantLoads {
+				t.Errorf("GetOrLoad() loads = %d, want %d", got, tt.wantLoads)
+			}
+		})
+	}
+}
+
+func TestCache_Metrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	c := New[string, string]("test", Config{Enabled: true, TTL: time.Hour}, newTestBackend(t), WithMeterProvider(mp))
+	loader := func(ctx context.Context, key string) (string, error) { return "test-value", nil }
+
+	for range 3 {
+		if _, err := c.GetOrLoad(t.Context(), "test-key", loader); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				got[m.Name] = data.DataPoints[0].Value
+			case metricdata.Histogram[float64]:
+				got[m.Name] = int64(data.DataPoints[0].Count)
+			}
+		}
+	}
+	want := map[string]int64{"cache.hits": 2, "cache.misses": 1, "cache.load.duration": 1}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("metric %s = %d, want %d", name, got[name], value)
+		}
+	}
+}
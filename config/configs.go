@@ -22,6 +22,12 @@ func (c *AppConfig) Init() {
 	c.Version = buildVersion(info)
 }
 
+// environment is promoted to configs that embed AppConfig so environment
+// overlays can find it.
+func (c AppConfig) environment() string {
+	return c.Environment
+}
+
 func buildVersion(info *debug.BuildInfo) string {
 	if info.Main.Version != "" && info.Main.Version != "(devel)" {
 		return info.Main.Version
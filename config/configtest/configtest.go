@@ -0,0 +1,19 @@
+// Package configtest provides test doubles for the config package.
+package configtest
+
+import (
+	"context"
+	"fmt"
+)
+
+// SecretResolver is a config.SecretResolver that resolves references from a
+// map.
+type SecretResolver map[string]string
+
+func (s SecretResolver) Resolve(_ context.Context, ref string) (string, error) {
+	v, ok := s[ref]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", ref)
+	}
+	return v, nil
+}
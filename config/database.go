@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+)
+
+// Pinger is implemented by *sql.DB, *sql.Conn and *sqlx.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingFunc adapts a ping function, such as (*pgxpool.Pool).Ping, to a Pinger.
+type PingFunc func(ctx context.Context) error
+
+// PingContext calls f.
+func (f PingFunc) PingContext(ctx context.Context) error {
+	return f(ctx)
+}
+
+// DBHealthChecker reports whether a database is reachable by pinging it. It
+// implements handlers.HealthChecker for *sql.DB, *sqlx.DB and, through
+// PingFunc, *pgxpool.Pool.
+type DBHealthChecker struct {
+	db Pinger
+}
+
+// NewDBHealthChecker creates a health checker for db.
+func NewDBHealthChecker(db Pinger) *DBHealthChecker {
+	return &DBHealthChecker{db: db}
+}
+
+// Healthy pings the database.
+func (c *DBHealthChecker) Healthy(ctx context.Context) error {
+	if err := c.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// connectWithRetry calls connect until it succeeds, backing off exponentially
+// between attempts, for up to timeout. A timeout of 0 makes a single attempt.
+func connectWithRetry(ctx context.Context, name string, timeout time.Duration, connect func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return connect(ctx)
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = 5 * time.Second
+	_, err := backoff.Retry(ctx, func() (struct{}, error) {
+		return struct{}{}, connect(ctx)
+	},
+		backoff.WithBackOff(b),
+		backoff.WithMaxElapsedTime(timeout),
+		backoff.WithNotify(func(err error, next time.Duration) {
+			slog.WarnContext(ctx, "failed to connect to "+name+", retrying", "err", err, "retry_in", next)
+		}),
+	)
+	return err
+}
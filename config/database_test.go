@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDBHealthChecker_Healthy(t *testing.T) {
+	tests := []struct {
+		name    string
+		pingErr error
+		wantErr bool
+	}{
+		{
+			name: "healthy",
+		},
+		{
+			name:    "unhealthy",
+			pingErr: errors.New("test-error"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			mock.ExpectPing().WillReturnError(tt.pingErr)
+
+			err = NewDBHealthChecker(db).Healthy(t.Context())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Healthy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDBHealthChecker_PingFunc(t *testing.T) {
+	err := NewDBHealthChecker(PingFunc(func(context.Context) error {
+		return errors.New("test-error")
+	})).Healthy(t.Context())
+	if err == nil {
+		t.Error("Healthy() expected an error")
+	}
+}
+
+func TestConnectWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeout   time.Duration
+		failures  int
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "connected",
+			timeout:   time.Second,
+			wantCalls: 1,
+		},
+		{
+			name:      "retried",
+			timeout:   5 * time.Second,
+			failures:  1,
+			wantCalls: 2,
+		},
+		{
+			name:      "no retries",
+			failures:  1,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "timed out",
+			timeout:   100 * time.Millisecond,
+			failures:  10,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			err := connectWithRetry(t.Context(), "test-db", tt.timeout, func(context.Context) error {
+				calls++
+				if calls <= tt.failures {
+					return errors.New("test-error")
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("connectWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("connectWithRetry() calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
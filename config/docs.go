@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alexflint/go-arg"
+)
+
+// ErrHelp is returned by New when the CLI args ask for help (-h or --help).
+var ErrHelp = arg.ErrHelp
+
+// FieldDoc documents a config field and the keys each source uses to set it.
+type FieldDoc struct {
+	Name     string `json:"name"`
+	Env      string `json:"env"`
+	Flag     string `json:"flag,omitempty"`
+	File     string `json:"file,omitempty"`
+	Type     string `json:"type"`
+	Default  string `json:"default,omitempty"`
+	Required bool   `json:"required"`
+	Secret   bool   `json:"secret,omitempty"`
+	Help     string `json:"help,omitempty"`
+}
+
+// Docs documents every field of a config of type T, including the fields of
+// embedded and nested structs. Pass the same options as New so env vars are
+// documented with their prefix.
+func Docs[T any](opts ...Option) []FieldDoc {
+	o := newOptions(opts)
+	var docs []FieldDoc
+	for _, f := range fields(reflect.TypeFor[T](), o.prefix) {
+		docs = append(docs, FieldDoc{
+			Name:     f.Name,
+			Env:      f.Env,
+			Flag:     f.Flag,
+			File:     f.File,
+			Type:     f.Type.String(),
+			Default:  f.Default,
+			Required: f.Required || slices.Contains(strings.Split(f.Validate, ","), "required"),
+			Secret:   f.Secret,
+			Help:     f.Help,
+		})
+	}
+	return docs
+}
+
+// WriteDocs writes docs as a "markdown" table or a "json" array.
+func WriteDocs(w io.Writer, docs []FieldDoc, format string) error {
+	switch format {
+	case "markdown", "md":
+		return writeMarkdown(w, docs)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(docs)
+	default:
+		return fmt.Errorf("unsupported config docs format: %s", format)
+	}
+}
+
+func writeMarkdown(w io.Writer, docs []FieldDoc) error {
+	var b strings.Builder
+	b.WriteString("| Env Var | Flag | File Key | Type | Default | Required | Description |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, d := range docs {
+		required := ""
+		if d.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s |\n",
+			code(d.Env), code(d.Flag), code(d.File), code(d.Type), code(d.Default), required,
+			strings.ReplaceAll(d.Help, "|", `\|`))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// WriteHelp writes CLI help for the program name, listing every field with
+// its flag, env var, default and description.
+func WriteHelp(w io.Writer, name string, docs []FieldDoc) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Usage: %s [options]\n\nOptions:\n", name)
+	for _, d := range docs {
+		flag := d.Flag
+		if flag == "" {
+			flag = "-"
+		}
+		var details []string
+		if d.Help != "" {
+			details = append(details, d.Help)
+		}
+		if d.Default != "" {
+			details = append(details, "default: "+d.Default)
+		}
+		if d.Required {
+			details = append(details, "required")
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", flag, d.Env, d.Type, strings.Join(details, ", "))
+	}
+	fmt.Fprintf(tw, "  %s\t\t\t%s\n", "-h, --help", "display this help and exit")
+	return tw.Flush()
+}
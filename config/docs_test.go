@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDocsConfig struct {
+	AppConfig
+	Host     string        `envconfig:"HOST" default:"localhost" help:"server host"`
+	Timeout  time.Duration `envconfig:"TIMEOUT" default:"30s"`
+	Password string        `envconfig:"PASSWORD" validate:"required" secret:"true" arg:"-"`
+}
+
+func TestDocs(t *testing.T) {
+	want := []FieldDoc{
+		{Name: "AppConfig.Environment", Env: "TEST_ENVIRONMENT", Flag: "--environment", File: "environment", Type: "string"},
+		{Name: "AppConfig.Name", Env: "TEST_APP_NAME", Flag: "--name", File: "name", Type: "string"},
+		{Name: "AppConfig.Version", Env: "TEST_VERSION", Flag: "--version", File: "version", Type: "string"},
+		{Name: "Host", Env: "TEST_HOST", Flag: "--host", File: "host", Type: "string", Default: "localhost", Help: "server host"},
+		{Name: "Timeout", Env: "TEST_TIMEOUT", Flag: "--timeout", File: "timeout", Type: "time.Duration", Default: "30s"},
+		{Name: "Password", Env: "TEST_PASSWORD", File: "password", Type: "string", Required: true, Secret: true},
+	}
+	if got := Docs[testDocsConfig](WithPrefix("test")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Docs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteDocs(t *testing.T) {
+	docs := []FieldDoc{
+		{Name: "Host", Env: "HOST", Flag: "--host", File: "host", Type: "string", Default: "localhost", Help: "server host"},
+		{Name: "Password", Env: "PASSWORD", File: "password", Type: "string", Required: true, Secret: true},
+	}
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "markdown",
+			format: "markdown",
+			want: "| Env Var | Flag | File Key | Type | Default | Required | Description |\n" +
+				"|---|---|---|---|---|---|---|\n" +
+				"| `HOST` | `--host` | `host` | `string` | `localhost` |  | server host |\n" +
+				"| `PASSWORD` |  | `password` | `string` |  | yes |  |\n",
+		},
+		{
+			name:   "json",
+			format: "json",
+			want: `[
+  {
+    "name": "Host",
+    "env": "HOST",
+    "flag": "--host",
+    "file": "host",
+    "type": "string",
+    "default": "localhost",
+    "required": false,
+    "help": "server host"
+  },
+  {
+    "name": "Password",
+    "env": "PASSWORD",
+    "file": "password",
+    "type": "string",
+    "required": true,
+    "secret": true
+  }
+]
+`,
+		},
+		{
+			name:    "unsupported",
+			format:  "html",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := WriteDocs(&b, docs, tt.format); (err != nil) != tt.wantErr {
+				t.Fatalf("WriteDocs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("WriteDocs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteHelp(t *testing.T) {
+	docs := []FieldDoc{
+		{Name: "Host", Env: "HOST", Flag: "--host", Type: "string", Default: "localhost", Help: "server host"},
+		{Name: "Password", Env: "PASSWORD", Type: "string", Required: true},
+	}
+	want := "Usage: test-app [options]\n\nOptions:\n" +
+		"  --host      HOST      string  server host, default: localhost\n" +
+		"  -           PASSWORD  string  required\n" +
+		"  -h, --help                    display this help and exit\n"
+
+	var b strings.Builder
+	if err := WriteHelp(&b, "test-app", docs); err != nil {
+		t.Fatalf("WriteHelp() error = %v", err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("WriteHelp() = %q, want %q", got, want)
+	}
+}
+
+func TestNewHelp(t *testing.T) {
+	orig := os.Args
+	os.Args = []string{"test", "--help"}
+	t.Cleanup(func() { os.Args = orig })
+
+	if _, err := New[testDocsConfig](); !errors.Is(err, ErrHelp) {
+		t.Errorf("New() error = %v, want ErrHelp", err)
+	}
+}
+
+func TestNewHelp_MissingRequired(t *testing.T) {
+	type testRequiredConfig struct {
+		Host string `envconfig:"TEST_REQUIRED_HOST" required:"true"`
+	}
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "help", args: []string{"test", "--help"}, want: true},
+		{name: "short help", args: []string{"test", "-h"}, want: true},
+		{name: "after terminator", args: []string{"test", "--", "--help"}, want: false},
+		{name: "no help", args: []string{"test"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := os.Args
+			os.Args = tt.args
+			t.Cleanup(func() { os.Args = orig })
+
+			_, err := New[testRequiredConfig]()
+			if got := errors.Is(err, ErrHelp); got != tt.want {
+				t.Errorf("New() error = %v, want ErrHelp %v", err, tt.want)
+			}
+			if err == nil {
+				t.Error("New() error = nil, want an error")
+			}
+		})
+	}
+}
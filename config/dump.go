@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces the value of secret fields that are set.
+const redacted = "REDACTED"
+
+// Redact returns the effective config as nested maps keyed by file keys, so it
+// reads like a config file. Fields tagged secret:"true" that are set are
+// masked, and durations and text marshalers are rendered as strings.
+func Redact(cfg any) map[string]any {
+	out := map[string]any{}
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return out
+		}
+		v = v.Elem()
+	}
+	for _, f := range fields(v.Type(), "") {
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue
+		}
+		value := dumpValue(fv)
+		if f.Secret && !fv.IsZero() {
+			value = redacted
+		}
+		setPath(out, strings.Split(f.key(), "."), value)
+	}
+	return out
+}
+
+// Dump renders the effective config with Redact in the given format,
+// either "json" or "yaml".
+func Dump(cfg any, format string) ([]byte, error) {
+	switch strings.TrimPrefix(format, ".") {
+	case "json":
+		return json.MarshalIndent(Redact(cfg), "", "  ")
+	case "yaml", "yml":
+		return yaml.Marshal(Redact(cfg))
+	default:
+		return nil, fmt.Errorf("unsupported config dump format: %s", format)
+	}
+}
+
+func dumpValue(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String()
+	case encoding.TextMarshaler:
+		if b, err := x.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			if b, err := m.MarshalText(); err == nil {
+				return string(b)
+			}
+		}
+	}
+	return v.Interface()
+}
+
+func setPath(m map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
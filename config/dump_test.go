@@ -0,0 +1,84 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDumpConfig struct {
+	AppConfig
+	Postgres PostgresConfig `json:"postgres"`
+	Token    string         `envconfig:"TOKEN" json:"token" secret:"true"`
+	Timeout  time.Duration  `envconfig:"TIMEOUT"`
+}
+
+func TestRedact(t *testing.T) {
+	cfg := testDumpConfig{
+		AppConfig: AppConfig{Name: "test-app"},
+		Postgres:  PostgresConfig{Host: "test-host", Password: "test-password"},
+		Timeout:   5 * time.Second,
+	}
+	got := Redact(&cfg)
+
+	if got["name"] != "test-app" {
+		t.Errorf("name = %v, want test-app", got["name"])
+	}
+	if got["timeout"] != "5s" {
+		t.Errorf("timeout = %v, want 5s", got["timeout"])
+	}
+	if got["token"] != "" {
+		t.Errorf("token = %v, want unset secret left empty", got["token"])
+	}
+	pg, ok := got["postgres"].(map[string]any)
+	if !ok {
+		t.Fatalf("postgres = %T, want nested map", got["postgres"])
+	}
+	if pg["host"] != "test-host" {
+		t.Errorf("postgres.host = %v, want test-host", pg["host"])
+	}
+	if pg["password"] != redacted {
+		t.Errorf("postgres.password = %v, want %s", pg["password"], redacted)
+	}
+}
+
+func TestDump(t *testing.T) {
+	cfg := struct {
+		Host     string `json:"host"`
+		Password string `json:"password" secret:"true"`
+	}{Host: "test-host", Password: "test-password"}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "json",
+			format: "json",
+			want:   "{\n  \"host\": \"test-host\",\n  \"password\": \"REDACTED\"\n}",
+		},
+		{
+			name:   "yaml",
+			format: "yaml",
+			want:   "host: test-host\npassword: REDACTED\n",
+		},
+		{
+			name:    "unsupported",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Dump(cfg, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(string(got), tt.want) {
+				t.Errorf("Dump() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// field describes a config struct field and the keys each source uses to set it.
+type field struct {
+	// Name is the Go path to the field, including embedded struct names (e.g. "AppConfig.Name").
+	Name  string
+	Index []int
+	Type  reflect.Type
+	Env   string
+	// Lookup is every env var envconfig reads the field from, in the order it
+	// tries them: the prefixed key, then the unprefixed tag name.
+	Lookup   []string
+	File     string
+	Flag     string
+	Default  string
+	Required bool
+	Secret   bool
+	Validate string
+	Help     string
+}
+
+// fields walks the struct type t the same way envconfig, the file decoders and
+// go-arg do, returning every leaf field with the env var, file key and CLI flag
+// that set it. Non-struct types have no fields.
+func fields(t reflect.Type, prefix string) []field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var out []field
+	walkFields(t, walkState{envPrefix: strings.ToUpper(prefix), flags: true}, &out)
+	return out
+}
+
+type walkState struct {
+	name      string
+	index     []int
+	envPrefix string
+	// nested is set once the walk enters a named (non-embedded) struct field,
+	// where envconfig also accepts the unprefixed tag name.
+	nested bool
+	file   string
+	// flags is cleared when go-arg would not expose the fields as flags.
+	flags bool
+}
+
+func walkFields(t reflect.Type, s walkState, out *[]field) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() || isTrue(sf.Tag.Get("ignored")) {
+			continue
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		tag := strings.ToUpper(sf.Tag.Get("envconfig"))
+		key := tag
+		if key == "" {
+			key = strings.ToUpper(sf.Name)
+		}
+		if s.envPrefix != "" {
+			key = s.envPrefix + "_" + key
+		}
+
+		next := walkState{
+			name:      joinPath(s.name, sf.Name),
+			index:     append(append([]int{}, s.index...), i),
+			envPrefix: s.envPrefix,
+			nested:    s.nested,
+			file:      s.file,
+			flags:     s.flags && sf.Tag.Get("arg") != "-",
+		}
+
+		if ft.Kind() == reflect.Struct && !decodable(ft) {
+			if !sf.Anonymous {
+				next.envPrefix = key
+				next.nested = true
+				next.file = joinPath(s.file, fileKey(sf))
+				// go-arg only flattens embedded structs.
+				next.flags = false
+			} else if name, ok := jsonName(sf); ok && name != "" {
+				next.file = joinPath(s.file, name)
+			}
+			walkFields(ft, next, out)
+			continue
+		}
+
+		env := key
+		if s.nested && tag != "" {
+			env = tag
+		}
+
+		lookup := []string{key}
+		if tag != "" && tag != key {
+			lookup = append(lookup, tag)
+		}
+
+		f := field{
+			Name:     next.name,
+			Index:    next.index,
+			Type:     sf.Type,
+			Env:      env,
+			Lookup:   lookup,
+			File:     joinPath(s.file, fileKey(sf)),
+			Default:  sf.Tag.Get("default"),
+			Required: isTrue(sf.Tag.Get("required")),
+			Secret:   isTrue(sf.Tag.Get("secret")),
+			Validate: sf.Tag.Get("validate"),
+			Help:     sf.Tag.Get("help"),
+		}
+		if name, _ := jsonName(sf); name == "-" {
+			f.File = ""
+		}
+		if next.flags {
+			f.Flag = "--" + flagName(sf)
+		}
+		*out = append(*out, f)
+	}
+}
+
+// decodable reports whether envconfig treats a struct type as a single value.
+func decodable(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return p.Implements(reflect.TypeFor[envconfig.Decoder]()) ||
+		p.Implements(reflect.TypeFor[envconfig.Setter]()) ||
+		p.Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) ||
+		p.Implements(reflect.TypeFor[encoding.BinaryUnmarshaler]())
+}
+
+func jsonName(sf reflect.StructField) (string, bool) {
+	tag, ok := sf.Tag.Lookup("json")
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+func fileKey(sf reflect.StructField) string {
+	if name, ok := jsonName(sf); ok && name != "" && name != "-" {
+		return name
+	}
+	return strings.ToLower(sf.Name)
+}
+
+func flagName(sf reflect.StructField) string {
+	for _, key := range strings.Split(sf.Tag.Get("arg"), ",") {
+		key = strings.TrimSpace(key)
+		if strings.HasPrefix(key, "--") {
+			return key[2:]
+		}
+	}
+	return strings.ToLower(sf.Name)
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func isTrue(s string) bool {
+	b, _ := strconv.ParseBool(s)
+	return b
+}
+
+// key returns the dotted key that identifies the field in provenance and
+// config dumps. It matches the file key unless the field can't be set by a file.
+func (f field) key() string {
+	if f.File != "" {
+		return f.File
+	}
+	return strings.ToLower(f.Name)
+}
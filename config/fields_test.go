@@ -0,0 +1,75 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	type testFieldsSub struct {
+		Value int `envconfig:"TEST_VALUE" default:"1"`
+		Other string
+	}
+	type testFieldsConfig struct {
+		AppConfig
+		Host   string        `envconfig:"HOST" default:"localhost" required:"true" help:"server host"`
+		Port   int           `envconfig:"PORT" json:"listen_port" arg:"--listen"`
+		Secret string        `envconfig:"SECRET" json:"-" arg:"-" secret:"true"`
+		Key    RSAPublicKey  `envconfig:"KEY"`
+		Sub    testFieldsSub `json:"sub"`
+		hidden string
+	}
+	tests := []struct {
+		name   string
+		prefix string
+		want   []field
+	}{
+		{
+			name: "no prefix",
+			want: []field{
+				{Name: "AppConfig.Environment", Env: "ENVIRONMENT", Lookup: []string{"ENVIRONMENT"}, File: "environment", Flag: "--environment"},
+				{Name: "AppConfig.Name", Env: "APP_NAME", Lookup: []string{"APP_NAME"}, File: "name", Flag: "--name"},
+				{Name: "AppConfig.Version", Env: "VERSION", Lookup: []string{"VERSION"}, File: "version", Flag: "--version"},
+				{Name: "Host", Env: "HOST", Lookup: []string{"HOST"}, File: "host", Flag: "--host", Default: "localhost", Required: true, Help: "server host"},
+				{Name: "Port", Env: "PORT", Lookup: []string{"PORT"}, File: "listen_port", Flag: "--listen"},
+				{Name: "Secret", Env: "SECRET", Lookup: []string{"SECRET"}, Secret: true},
+				{Name: "Key", Env: "KEY", Lookup: []string{"KEY"}, File: "key", Flag: "--key"},
+				{Name: "Sub.Value", Env: "TEST_VALUE", Lookup: []string{"SUB_TEST_VALUE", "TEST_VALUE"}, File: "sub.value", Default: "1"},
+				{Name: "Sub.Other", Env: "SUB_OTHER", Lookup: []string{"SUB_OTHER"}, File: "sub.other"},
+			},
+		},
+		{
+			name:   "prefix",
+			prefix: "test",
+			want: []field{
+				{Name: "AppConfig.Environment", Env: "TEST_ENVIRONMENT", Lookup: []string{"TEST_ENVIRONMENT", "ENVIRONMENT"}, File: "environment", Flag: "--environment"},
+				{Name: "AppConfig.Name", Env: "TEST_APP_NAME", Lookup: []string{"TEST_APP_NAME", "APP_NAME"}, File: "name", Flag: "--name"},
+				{Name: "AppConfig.Version", Env: "TEST_VERSION", Lookup: []string{"TEST_VERSION", "VERSION"}, File: "version", Flag: "--version"},
+				{Name: "Host", Env: "TEST_HOST", Lookup: []string{"TEST_HOST", "HOST"}, File: "host", Flag: "--host", Default: "localhost", Required: true, Help: "server host"},
+				{Name: "Port", Env: "TEST_PORT", Lookup: []string{"TEST_PORT", "PORT"}, File: "listen_port", Flag: "--listen"},
+				{Name: "Secret", Env: "TEST_SECRET", Lookup: []string{"TEST_SECRET", "SECRET"}, Secret: true},
+				{Name: "Key", Env: "TEST_KEY", Lookup: []string{"TEST_KEY", "KEY"}, File: "key", Flag: "--key"},
+				{Name: "Sub.Value", Env: "TEST_VALUE", Lookup: []string{"TEST_SUB_TEST_VALUE", "TEST_VALUE"}, File: "sub.value", Default: "1"},
+				{Name: "Sub.Other", Env: "TEST_SUB_OTHER", Lookup: []string{"TEST_SUB_OTHER"}, File: "sub.other"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(reflect.TypeFor[testFieldsConfig](), tt.prefix)
+			for i := range got {
+				got[i].Index = nil
+				got[i].Type = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("non struct", func(t *testing.T) {
+		if got := fields(reflect.TypeFor[map[string]string](), ""); got != nil {
+			t.Errorf("fields() = %+v, want nil", got)
+		}
+	})
+}
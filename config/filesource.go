@@ -0,0 +1,248 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	"github.com/joho/godotenv"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// fileExts are the config file formats loadFile supports.
+var fileExts = []string{".json", ".yaml", ".yml", ".toml", ".hcl", ".env"}
+
+// fileSource is a config file or a directory of config files added by
+// WithFile or WithDir.
+type fileSource struct {
+	path string
+	dir  bool
+}
+
+// paths returns the config files of the source. A directory's files are
+// returned in lexical order; a missing directory has none.
+func (s fileSource) paths() ([]string, error) {
+	if !s.dir {
+		return []string{s.path}, nil
+	}
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && supportedFile(e.Name()) {
+			paths = append(paths, filepath.Join(s.path, e.Name()))
+		}
+	}
+	return paths, nil
+}
+
+// watches reports whether a change to the file name affects the source.
+func (s fileSource) watches(name string, overlay bool) bool {
+	name = filepath.Clean(name)
+	path := filepath.Clean(s.path)
+	if s.dir {
+		return filepath.Dir(name) == path && supportedFile(name)
+	}
+	if name == path {
+		return true
+	}
+	if !overlay || filepath.Dir(name) != filepath.Dir(path) {
+		return false
+	}
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext) + "."
+	return strings.HasPrefix(name, stem) && strings.HasSuffix(name, ext)
+}
+
+func supportedFile(name string) bool {
+	return slices.Contains(fileExts, filepath.Ext(name))
+}
+
+// overlayPath returns the environment overlay of a config file,
+// e.g. config.production.yaml for config.yaml.
+func overlayPath(path, environment string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + environment + ext
+}
+
+// environment returns the environment the config is loaded for: the
+// AppConfig.Environment of the config if it embeds AppConfig, or else the
+// ENVIRONMENT env var.
+func environment(cfg any, prefix string) string {
+	if a, ok := cfg.(interface{ environment() string }); ok {
+		return a.environment()
+	}
+	if prefix != "" {
+		if v, ok := os.LookupEnv(strings.ToUpper(prefix) + "_ENVIRONMENT"); ok {
+			return v
+		}
+	}
+	return os.Getenv("ENVIRONMENT")
+}
+
+func loadFile(path string, cfg any, prefix string) error {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	ext := filepath.Ext(path)
+	switch ext {
+	case ".json":
+		return json.Unmarshal(data, cfg)
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	case ".toml":
+		return toml.Unmarshal(data, cfg)
+	case ".hcl":
+		return hcl.Unmarshal(data, cfg)
+	case ".env":
+		return loadEnvFile(data, cfg, prefix)
+	default:
+		return fmt.Errorf("unsupported config file format: %s", ext)
+	}
+}
+
+// loadEnvFile sets the fields of cfg from the env vars in a .env file,
+// using the same keys and value parsing as envconfig.
+func loadEnvFile(data []byte, cfg any, prefix string) error {
+	vars, err := parseEnvFile(data)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(cfg).Elem()
+	for _, f := range fields(v.Type(), prefix) {
+		value, ok := vars[f.Env]
+		if !ok {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue
+		}
+		if err := setValue(fv, value); err != nil {
+			return fmt.Errorf("failed to set %s from %s: %w", f.Name, f.Env, err)
+		}
+	}
+	return nil
+}
+
+// parseEnvFile returns the env vars set by a .env file.
+func parseEnvFile(data []byte) (map[string]string, error) {
+	return godotenv.UnmarshalBytes(data)
+}
+
+// setValue parses value into v the way envconfig parses env vars.
+func setValue(v reflect.Value, value string) error {
+	if v.CanAddr() {
+		switch u := v.Addr().Interface().(type) {
+		case envconfig.Decoder:
+			return u.Decode(value)
+		case envconfig.Setter:
+			return u.Set(value)
+		case encoding.TextUnmarshaler:
+			return u.UnmarshalText([]byte(value))
+		case encoding.BinaryUnmarshaler:
+			return u.UnmarshalBinary([]byte(value))
+		}
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), value)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeFor[time.Duration]() {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(value))
+			return nil
+		}
+		sl := reflect.MakeSlice(v.Type(), 0, 0)
+		if strings.TrimSpace(value) != "" {
+			vals := strings.Split(value, ",")
+			sl = reflect.MakeSlice(v.Type(), len(vals), len(vals))
+			for i, val := range vals {
+				if err := setValue(sl.Index(i), val); err != nil {
+					return err
+				}
+			}
+		}
+		v.Set(sl)
+	case reflect.Map:
+		mp := reflect.MakeMap(v.Type())
+		if strings.TrimSpace(value) != "" {
+			for _, pair := range strings.Split(value, ",") {
+				k, val, ok := strings.Cut(pair, ":")
+				if !ok {
+					return fmt.Errorf("invalid map item: %q", pair)
+				}
+				key := reflect.New(v.Type().Key()).Elem()
+				if err := setValue(key, k); err != nil {
+					return err
+				}
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if err := setValue(elem, val); err != nil {
+					return err
+				}
+				mp.SetMapIndex(key, elem)
+			}
+		}
+		v.Set(mp)
+	}
+	return nil
+}
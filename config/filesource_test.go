@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestFileSource_watches(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     fileSource
+		file    string
+		overlay bool
+		want    bool
+	}{
+		{name: "file", src: fileSource{path: "conf/config.yaml"}, file: "conf/config.yaml", want: true},
+		{name: "other file", src: fileSource{path: "conf/config.yaml"}, file: "conf/other.yaml"},
+		{name: "overlay", src: fileSource{path: "conf/config.yaml"}, file: "conf/config.production.yaml", overlay: true, want: true},
+		{name: "overlay disabled", src: fileSource{path: "conf/config.yaml"}, file: "conf/config.production.yaml"},
+		{name: "dir file", src: fileSource{path: "conf.d", dir: true}, file: "conf.d/10-base.toml", want: true},
+		{name: "dir unsupported file", src: fileSource{path: "conf.d", dir: true}, file: "conf.d/notes.txt"},
+		{name: "dir subdirectory file", src: fileSource{path: "conf.d", dir: true}, file: "conf.d/sub/10-base.toml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.src.watches(tt.file, tt.overlay); got != tt.want {
+				t.Errorf("watches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
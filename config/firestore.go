@@ -2,19 +2,97 @@ package config
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
-	firestore "cloud.google.com/go/firestore/apiv1"
+	"cloud.google.com/go/firestore"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 type FirestoreConfig struct {
-	APIKey string `envconfig:"FIRESTORE_API_KEY"`
+	ProjectID       string `envconfig:"FIRESTORE_PROJECT_ID" help:"Google Cloud project, detected from the credentials or environment when empty"`
+	Database        string `envconfig:"FIRESTORE_DATABASE" default:"(default)"`
+	CredentialsFile string `envconfig:"FIRESTORE_CREDENTIALS_FILE" help:"service account credentials file, Application Default Credentials when empty"`
+	APIKey          string `envconfig:"FIRESTORE_API_KEY" secret:"true"`
+	EmulatorHost    string `envconfig:"FIRESTORE_EMULATOR_HOST" help:"host:port of a local Firestore emulator"`
 }
 
-func NewFirestoreClient(cfg FirestoreConfig) (*firestore.Client, error) {
-	firestoreClient, err := firestore.NewClient(context.Background(), option.WithAPIKey(cfg.APIKey))
+func (cfg FirestoreConfig) clientOptions() []option.ClientOption {
+	opts := []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithStatsHandler(otelgrpc.NewClientHandler())),
+	}
+	if cfg.EmulatorHost != "" {
+		// Connect the way the Firestore client does for FIRESTORE_EMULATOR_HOST,
+		// without setting the process-wide env var.
+		return append(opts,
+			option.WithEndpoint(cfg.EmulatorHost),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+			option.WithGRPCDialOption(grpc.WithPerRPCCredentials(emulatorCredentials{})),
+		)
+	}
+	if cfg.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+	}
+	if cfg.APIKey != "" {
+		opts = append(opts, option.WithAPIKey(cfg.APIKey))
+	}
+	return opts
+}
+
+// NewFirestoreClient creates a Firestore client for the project and database,
+// authenticated with the credentials file, the API key or Application Default
+// Credentials. gRPC calls are traced and measured with OpenTelemetry. When
+// EmulatorHost is set the client connects to the emulator without
+// credentials.
+func NewFirestoreClient(ctx context.Context, cfg FirestoreConfig) (*firestore.Client, error) {
+	projectID := cfg.ProjectID
+	if projectID == "" {
+		projectID = firestore.DetectProjectID
+		if cfg.EmulatorHost != "" {
+			// The emulator accepts any project, as the Firestore client assumes.
+			projectID = "dummy-emulator-firestore-project"
+		}
+	}
+	client, err := firestore.NewClientWithDatabase(ctx, projectID, cfg.Database, cfg.clientOptions()...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create firestore client: %w", err)
+	}
+	return client, nil
+}
+
+// emulatorCredentials authenticate as an admin of the Firestore emulator, which
+// accepts "Bearer owner" as admin credentials.
+type emulatorCredentials struct{}
+
+func (emulatorCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer owner"}, nil
+}
+
+func (emulatorCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// FirestoreHealthChecker reports whether Firestore is reachable by listing a
+// collection. It implements handlers.HealthChecker.
+type FirestoreHealthChecker struct {
+	client *firestore.Client
+}
+
+// NewFirestoreHealthChecker creates a health checker for client.
+func NewFirestoreHealthChecker(client *firestore.Client) *FirestoreHealthChecker {
+	return &FirestoreHealthChecker{client: client}
+}
+
+// Healthy lists the first collection of the database.
+func (c *FirestoreHealthChecker) Healthy(ctx context.Context) error {
+	_, err := c.client.Collections(ctx).Next()
+	if err != nil && !errors.Is(err, iterator.Done) {
+		return fmt.Errorf("failed to list firestore collections: %w", err)
 	}
-	return firestoreClient, nil
+	return nil
 }
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewFirestoreClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     FirestoreConfig
+		wantErr bool
+	}{
+		{
+			name: "emulator",
+			cfg:  FirestoreConfig{ProjectID: "test-project", Database: "(default)", EmulatorHost: "127.0.0.1:1"},
+		},
+		{
+			name:    "missing credentials file",
+			cfg:     FirestoreConfig{ProjectID: "test-project", Database: "(default)", CredentialsFile: "testdata/missing.json"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FIRESTORE_EMULATOR_HOST", "")
+			got, err := NewFirestoreClient(t.Context(), tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFirestoreClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer got.Close()
+		})
+	}
+}
+
+func TestNewFirestoreClient_Emulator(t *testing.T) {
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "")
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorization := make(chan string, 1)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		select {
+		case authorization <- strings.Join(md.Get("authorization"), ","):
+		default:
+		}
+		return status.Error(codes.PermissionDenied, "test-error")
+	}))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	client, err := NewFirestoreClient(t.Context(), FirestoreConfig{
+		Database:     "(default)",
+		EmulatorHost: lis.Addr().String(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if got := os.Getenv("FIRESTORE_EMULATOR_HOST"); got != "" {
+		t.Errorf("FIRESTORE_EMULATOR_HOST = %q, want it unset", got)
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+	_ = NewFirestoreHealthChecker(client).Healthy(ctx)
+	select {
+	case got := <-authorization:
+		if got != "Bearer owner" {
+			t.Errorf("authorization = %q, want %q", got, "Bearer owner")
+		}
+	default:
+		t.Error("the emulator received no request")
+	}
+}
+
+func TestFirestoreHealthChecker_Healthy(t *testing.T) {
+	t.Setenv("FIRESTORE_EMULATOR_HOST", "")
+	client, err := NewFirestoreClient(t.Context(), FirestoreConfig{
+		ProjectID:    "test-project",
+		Database:     "(default)",
+		EmulatorHost: "127.0.0.1:1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	if err := NewFirestoreHealthChecker(client).Healthy(ctx); err == nil {
+		t.Error("Healthy() error = nil, want unreachable emulator error")
+	}
+}
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// Dialect is the SQL dialect a Migrator uses for its versions table and lock.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectMysql    Dialect = "mysql"
+)
+
+// Dialect returns the migration dialect for Postgres.
+func (PostgresConfig) Dialect() Dialect { return DialectPostgres }
+
+// Dialect returns the migration dialect for MySQL.
+func (MysqlConfig) Dialect() Dialect { return DialectMysql }
+
+func (d Dialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// migrationFile matches migration files named like 0001_create_users.up.sql.
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change read from a pair of up and down SQL
+// files.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator applies SQL migrations from a file system to a database, recording
+// the applied versions in a versions table. Migrations are serialized across
+// instances with an advisory lock, so every replica can run them at startup.
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations fs.FS
+	table      string
+}
+
+// MigratorOption configures a Migrator.
+type MigratorOption func(*Migrator)
+
+// WithMigrationsTable sets the name of the versions table, schema_migrations
+// by default.
+func WithMigrationsTable(table string) MigratorOption {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// NewMigrator creates a Migrator for the migrations at the root of fsys,
+// usually an embed.FS. Migrations are files named
+// <version>_<name>.up.sql and <version>_<name>.down.sql. Pass the *sql.DB of
+// an *sqlx.DB with db.DB. MySQL connections need multiStatements=true to run
+// files with more than one statement.
+func NewMigrator(db *sql.DB, dialect Dialect, fsys fs.FS, opts ...MigratorOption) *Migrator {
+	m := &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: fsys,
+		table:      "schema_migrations",
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Migrations returns the migrations in fsys, ordered by version.
+func (m *Migrator) Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+		b, err := fs.ReadFile(m.migrations, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(b)
+		} else {
+			migration.Down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return migrations, nil
+}
+
+// Up applies every migration that hasn't been applied, in version order.
+func (m *Migrator) Up(ctx context.Context) error {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return err
+	}
+	return m.locked(ctx, func(conn *sql.Conn, applied []int64) error {
+		for _, migration := range migrations {
+			if slices.Contains(applied, migration.Version) {
+				continue
+			}
+			insert := fmt.Sprintf("INSERT INTO %s (version, name) VALUES (%s, %s)",
+				m.table, m.dialect.placeholder(1), m.dialect.placeholder(2))
+			if err := m.exec(ctx, conn, migration.Up, insert, migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down rolls back the latest steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return err
+	}
+	return m.locked(ctx, func(conn *sql.Conn, applied []int64) error {
+		for i := len(applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+			idx := slices.IndexFunc(migrations, func(migration Migration) bool {
+				return migration.Version == applied[i]
+			})
+			if idx < 0 {
+				return fmt.Errorf("applied migration %d not found", applied[i])
+			}
+			migration := migrations[idx]
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			remove := fmt.Sprintf("DELETE FROM %s WHERE version = %s", m.table, m.dialect.placeholder(1))
+			if err := m.exec(ctx, conn, migration.Down, remove, migration.Version); err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Version returns the latest applied migration version, or 0 when none have
+// been applied.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	var version int64
+	err := m.locked(ctx, func(_ *sql.Conn, applied []int64) error {
+		if len(applied) > 0 {
+			version = applied[len(applied)-1]
+		}
+		return nil
+	})
+	return version, err
+}
+
+// locked runs fn on a single connection holding the advisory lock, after
+// creating the versions table and reading the applied versions.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, applied []int64) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := m.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, m.unlock(context.WithoutCancel(ctx), conn))
+	}()
+
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+
+		"version BIGINT PRIMARY KEY, "+
+		"name VARCHAR(255) NOT NULL, "+
+		"applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)", m.table)
+	if _, err := conn.ExecContext(ctx, create); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) ([]int64, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s ORDER BY version", m.table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []int64
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied = append(applied, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// exec runs a migration and the statement that records it in a transaction.
+// MySQL commits DDL implicitly, so a failed MySQL migration may be partially
+// applied.
+func (m *Migrator) exec(ctx context.Context, conn *sql.Conn, migration, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	switch m.dialect {
+	case DialectPostgres:
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", m.lockID()); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+	case DialectMysql:
+		var locked sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, -1)", m.table).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if locked.Int64 != 1 {
+			return errors.New("failed to acquire migration lock")
+		}
+	default:
+		return fmt.Errorf("unsupported migration dialect: %s", m.dialect)
+	}
+	return nil
+}
+
+func (m *Migrator) unlock(ctx context.Context, conn *sql.Conn) error {
+	var err error
+	switch m.dialect {
+	case DialectPostgres:
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", m.lockID())
+	case DialectMysql:
+		_, err = conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", m.table)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release migration lock: %w", err)
+	}
+	return nil
+}
+
+// lockID derives the Postgres advisory lock key from the versions table name.
+func (m *Migrator) lockID() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(m.table))
+	return int64(h.Sum64())
+}
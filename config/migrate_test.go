@@ -0,0 +1,144 @@
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var testMigrations = fstest.MapFS{
+	"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INT)")},
+	"0001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+	"0002_add_name.up.sql":       {Data: []byte("ALTER TABLE users ADD name TEXT")},
+	"0002_add_name.down.sql":     {Data: []byte("ALTER TABLE users DROP name")},
+	"README.md":                  {Data: []byte("test-readme")},
+}
+
+func TestMigrator_Migrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "migrations",
+			fsys: testMigrations,
+			want: []Migration{
+				{Version: 1, Name: "create_users", Up: "CREATE TABLE users (id INT)", Down: "DROP TABLE users"},
+				{Version: 2, Name: "add_name", Up: "ALTER TABLE users ADD name TEXT", Down: "ALTER TABLE users DROP name"},
+			},
+		},
+		{
+			name: "missing up",
+			fsys: fstest.MapFS{
+				"0001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"0001_create_users.up.sql": {Data: []byte("CREATE TABLE users (id INT)")},
+				"0001_create_posts.up.sql": {Data: []byte("CREATE TABLE posts (id INT)")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMigrator(nil, DialectPostgres, tt.fsys).Migrations()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Migrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Migrations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	tests := []struct {
+		name   string
+		config interface{ Dialect() Dialect }
+		lock   string
+		unlock string
+		insert string
+	}{
+		{
+			name:   "postgres",
+			config: PostgresConfig{},
+			lock:   "SELECT pg_advisory_lock($1)",
+			unlock: "SELECT pg_advisory_unlock($1)",
+			insert: "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+		},
+		{
+			name:   "mysql",
+			config: MysqlConfig{},
+			lock:   "SELECT GET_LOCK(?, -1)",
+			unlock: "SELECT RELEASE_LOCK(?)",
+			insert: "INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			if tt.config.Dialect() == DialectMysql {
+				mock.ExpectQuery(regexp.QuoteMeta(tt.lock)).WithArgs("schema_migrations").
+					WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(1))
+			} else {
+				mock.ExpectExec(regexp.QuoteMeta(tt.lock)).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT version FROM schema_migrations ORDER BY version").
+				WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE users ADD name TEXT")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(tt.insert)).WithArgs(2, "add_name").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectExec(regexp.QuoteMeta(tt.unlock)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			if err := NewMigrator(db, tt.config.Dialect(), testMigrations).Up(t.Context()); err != nil {
+				t.Fatalf("Up() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS test_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM test_migrations ORDER BY version").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1).AddRow(2))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE users DROP name")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM test_migrations WHERE version = $1")).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	m := NewMigrator(db, DialectPostgres, testMigrations, WithMigrationsTable("test_migrations"))
+	if err := m.Down(t.Context(), 1); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
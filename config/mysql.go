@@ -1,19 +1,23 @@
 package config
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/XSAM/otelsql"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 )
 
 type MysqlConfig struct {
-	Password string `envconfig:"MYSQL_PASSWORD"`
-	User     string `envconfig:"MYSQL_USER"     default:"mysql"`
-	Port     int    `envconfig:"MYSQL_PORT"     default:"3306"`
+	Password string `envconfig:"MYSQL_PASSWORD" secret:"true"`
+	User     string `envconfig:"MYSQL_USER"     default:"mysql"     validate:"required"`
+	Port     int    `envconfig:"MYSQL_PORT"     default:"3306"      validate:"min=1,max=65535"`
 	Database string `envconfig:"MYSQL_DB"`
-	Host     string `envconfig:"MYSQL_HOST"     default:"localhost"`
+	Host     string `envconfig:"MYSQL_HOST"     default:"localhost" validate:"required"`
+
+	ConnectTimeout time.Duration `envconfig:"MYSQL_CONNECT_TIMEOUT" default:"30s" validate:"min=0" help:"how long to retry the initial connection, 0 to try once"`
 }
 
 func (m MysqlConfig) ConnectionString() string {
@@ -27,6 +31,8 @@ func (m MysqlConfig) ConnectionString() string {
 	)
 }
 
+// NewMysqlClient connects to MySQL, retrying with exponential backoff for up
+// to ConnectTimeout while the database is unreachable.
 func NewMysqlClient(cfg MysqlConfig) (*sql.DB, error) {
 	db, err := otelsql.Open("mysql", cfg.ConnectionString(), otelsql.WithAttributes(
 		semconv.DBSystemMySQL,
@@ -35,6 +41,11 @@ func NewMysqlClient(cfg MysqlConfig) (*sql.DB, error) {
 		return nil, err
 	}
 
+	if err := connectWithRetry(context.Background(), "mysql", cfg.ConnectTimeout, db.PingContext); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to mysql: %w", err)
+	}
+
 	if _, err := otelsql.RegisterDBStatsMetrics(db, otelsql.WithAttributes(
 		semconv.DBSystemMySQL,
 	)); err != nil {
@@ -2,10 +2,18 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jesse0michael/pkg/logger"
+	"github.com/prometheus/otlptranslator"
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/contrib/instrumentation/host"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
@@ -13,54 +21,432 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenTelemetry exporter protocols.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+// OpenTelemetry exporters for each signal. The console exporter writes to
+// stdout and the none exporter drops the signal, so local development and
+// tests don't need a collector. The prometheus exporter is for metrics only
+// and can be listed alongside another metrics exporter (e.g. "otlp,prometheus").
+const (
+	ExporterOTLP       = "otlp"
+	ExporterConsole    = "console"
+	ExporterNone       = "none"
+	ExporterPrometheus = "prometheus"
 )
 
 // OpenTelemetryConfig is the configuration for the OpenTelemetry exporters.
 // It uses the environment variables defined by the OpenTelemetry Collector
 // but with different defaults, to make it easier to integrate into services.
+// Endpoints are host:port or, to set the scheme and path, a URL. The per-signal
+// endpoints override OpenTelemetryEndpoint, which defaults to localhost:4317
+// for gRPC and localhost:4318 for HTTP. Timeouts are integer milliseconds, as
+// in the spec. See Sampler for the sampling fields.
 // https://github.com/open-telemetry/opentelemetry-specification/blob/v1.20.0/specification/protocol/exporter.md
 type OpenTelemetryConfig struct {
-	OpenTelemetryEndpoint   string  `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"localhost:4317"`
-	OpenTelemetryInsecure   bool    `envconfig:"OTEL_EXPORTER_OTLP_INSECURE" default:"true"`
-	OpenTelemetrySampleRate float64 `envconfig:"OTEL_TRACES_SAMPLER_ARG" default:"1.0"`
+	OpenTelemetryEndpoint          string       `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OpenTelemetryTracesEndpoint    string       `envconfig:"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"`
+	OpenTelemetryMetricsEndpoint   string       `envconfig:"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"`
+	OpenTelemetryLogsEndpoint      string       `envconfig:"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"`
+	OpenTelemetryProtocol          string       `envconfig:"OTEL_EXPORTER_OTLP_PROTOCOL" default:"grpc" validate:"omitempty,oneof=grpc http/protobuf"`
+	OpenTelemetryInsecure          bool         `envconfig:"OTEL_EXPORTER_OTLP_INSECURE" default:"true"`
+	OpenTelemetryHeaders           Headers      `envconfig:"OTEL_EXPORTER_OTLP_HEADERS" secret:"true"`
+	OpenTelemetryCertificate       string       `envconfig:"OTEL_EXPORTER_OTLP_CERTIFICATE"`
+	OpenTelemetryClientCertificate string       `envconfig:"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"`
+	OpenTelemetryClientKey         string       `envconfig:"OTEL_EXPORTER_OTLP_CLIENT_KEY"`
+	OpenTelemetryCompression       string       `envconfig:"OTEL_EXPORTER_OTLP_COMPRESSION" default:"none" validate:"omitempty,oneof=gzip none"`
+	OpenTelemetryTimeout           Milliseconds `envconfig:"OTEL_EXPORTER_OTLP_TIMEOUT" default:"10000" validate:"min=0"`
+	OpenTelemetryTracesExporter    string       `envconfig:"OTEL_TRACES_EXPORTER" default:"otlp" validate:"omitempty,oneof=otlp console none"`
+	OpenTelemetryMetricsExporter   []string     `envconfig:"OTEL_METRICS_EXPORTER" default:"otlp" validate:"dive,oneof=otlp console none prometheus"`
+	OpenTelemetryLogsExporter      string       `envconfig:"OTEL_LOGS_EXPORTER" default:"otlp" validate:"omitempty,oneof=otlp console none"`
+	OpenTelemetrySampleRate        float64      `envconfig:"OTEL_TRACES_SAMPLER_ARG" default:"1.0" validate:"min=0,max=1"`
+	OpenTelemetrySampleRateLimit   float64      `envconfig:"OTEL_TRACES_SAMPLER_RATE_LIMIT" validate:"min=0"`
+	OpenTelemetrySampleAlways      []string     `envconfig:"OTEL_TRACES_SAMPLE_ALWAYS"`
+	OpenTelemetrySampleNever       []string     `envconfig:"OTEL_TRACES_SAMPLE_NEVER" default:"/health,/metrics"`
+	OpenTelemetrySampleErrors      bool         `envconfig:"OTEL_TRACES_SAMPLE_ERRORS"`
 }
 
-func (cfg OpenTelemetryConfig) MetricOptions() []otlpmetricgrpc.Option {
-	opts := []otlpmetricgrpc.Option{
-		otlpmetricgrpc.WithEndpoint(cfg.OpenTelemetryEndpoint),
+// Headers are OTLP exporter headers, decoded from the key1=value1,key2=value2
+// format the OpenTelemetry spec uses for OTEL_EXPORTER_OTLP_HEADERS.
+type Headers map[string]string
+
+// Decode headers from comma separated key=value pairs with URL encoded values.
+func (h *Headers) Decode(value string) error {
+	headers := Headers{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid header: %q", pair)
+		}
+		v, err := url.QueryUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("invalid header value for %s: %w", k, err)
+		}
+		headers[strings.TrimSpace(k)] = v
 	}
-	if cfg.OpenTelemetryInsecure {
-		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	*h = headers
+	return nil
+}
+
+// Milliseconds is a duration set in integer milliseconds, the unit the
+// OpenTelemetry spec uses for exporter timeouts.
+type Milliseconds time.Duration
+
+// Duration returns m as a time.Duration.
+func (m Milliseconds) Duration() time.Duration {
+	return time.Duration(m)
+}
+
+// Decode milliseconds from an integer.
+func (m *Milliseconds) Decode(value string) error {
+	ms, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid milliseconds %q: %w", value, err)
 	}
-	return opts
+	*m = Milliseconds(time.Duration(ms) * time.Millisecond)
+	return nil
 }
 
-func (cfg OpenTelemetryConfig) TracerOptions() []otlptracegrpc.Option {
-	opts := []otlptracegrpc.Option{
-		otlptracegrpc.WithEndpoint(cfg.OpenTelemetryEndpoint),
+func (m Milliseconds) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, time.Duration(m).Milliseconds(), 10), nil
+}
+
+func (m *Milliseconds) UnmarshalText(b []byte) error {
+	return m.Decode(string(b))
+}
+
+// UnmarshalJSON decodes milliseconds from a JSON number or string.
+func (m *Milliseconds) UnmarshalJSON(b []byte) error {
+	return m.Decode(strings.Trim(string(b), `"`))
+}
+
+// UnmarshalYAML decodes milliseconds from a YAML integer or string.
+func (m *Milliseconds) UnmarshalYAML(value *yaml.Node) error {
+	return m.Decode(value.Value)
+}
+
+// TLSConfig returns the TLS config for the CA and client certificate files,
+// or nil when none are set.
+func (cfg OpenTelemetryConfig) TLSConfig() (*tls.Config, error) {
+	if cfg.OpenTelemetryCertificate == "" && cfg.OpenTelemetryClientCertificate == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.OpenTelemetryCertificate != "" {
+		b, err := os.ReadFile(cfg.OpenTelemetryCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read otel certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("failed to parse otel certificate %s", cfg.OpenTelemetryCertificate)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.OpenTelemetryClientCertificate != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.OpenTelemetryClientCertificate, cfg.OpenTelemetryClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load otel client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// endpoint returns the endpoint for a signal and whether it is a URL. A
+// signal endpoint is used as is, while the HTTP signal path is appended to a
+// shared endpoint URL, as the OpenTelemetry spec describes.
+func (cfg OpenTelemetryConfig) endpoint(signalEndpoint, path string) (string, bool) {
+	if signalEndpoint != "" {
+		return signalEndpoint, strings.Contains(signalEndpoint, "://")
+	}
+	endpoint := cfg.OpenTelemetryEndpoint
+	if endpoint == "" {
+		endpoint = "localhost:4317"
+		if cfg.OpenTelemetryProtocol == ProtocolHTTP {
+			endpoint = "localhost:4318"
+		}
+	}
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, false
+	}
+	if cfg.OpenTelemetryProtocol == ProtocolHTTP {
+		endpoint = strings.TrimSuffix(endpoint, "/") + path
+	}
+	return endpoint, true
+}
+
+// otlpSettings are the OTLP exporter settings shared by every signal and
+// protocol, computed once from the config.
+type otlpSettings struct {
+	endpoint string
+	url      bool
+	tls      *tls.Config
+	insecure bool
+	headers  map[string]string
+	gzip     bool
+	timeout  time.Duration
+}
+
+// otlpSettings returns the exporter settings of a signal. TLS files that fail
+// to load are skipped here and reported by the signal's provider.
+func (cfg OpenTelemetryConfig) otlpSettings(signalEndpoint, path string) otlpSettings {
+	s := otlpSettings{
+		headers: cfg.OpenTelemetryHeaders,
+		gzip:    cfg.OpenTelemetryCompression == "gzip",
+		timeout: cfg.OpenTelemetryTimeout.Duration(),
+	}
+	s.endpoint, s.url = cfg.endpoint(signalEndpoint, path)
+	if tlsConfig, err := cfg.TLSConfig(); err == nil && tlsConfig != nil {
+		s.tls = tlsConfig
+	} else {
+		s.insecure = cfg.OpenTelemetryInsecure
+	}
+	return s
+}
+
+// otlpOptionFuncs are the option constructors of one OTLP exporter package.
+type otlpOptionFuncs[O any] struct {
+	endpoint    func(string) O
+	endpointURL func(string) O
+	tls         func(*tls.Config) O
+	insecure    func() O
+	headers     func(map[string]string) O
+	gzip        O
+	timeout     func(time.Duration) O
+}
+
+// otlpOptions maps the settings to the options of an OTLP exporter package.
+func otlpOptions[O any](s otlpSettings, f otlpOptionFuncs[O]) []O {
+	var opts []O
+	if s.url {
+		opts = append(opts, f.endpointURL(s.endpoint))
+	} else {
+		opts = append(opts, f.endpoint(s.endpoint))
+	}
+	if s.tls != nil {
+		opts = append(opts, f.tls(s.tls))
+	} else if s.insecure {
+		opts = append(opts, f.insecure())
 	}
-	if cfg.OpenTelemetryInsecure {
-		opts = append(opts, otlptracegrpc.WithInsecure())
+	if len(s.headers) > 0 {
+		opts = append(opts, f.headers(s.headers))
+	}
+	if s.gzip {
+		opts = append(opts, f.gzip)
+	}
+	if s.timeout > 0 {
+		opts = append(opts, f.timeout(s.timeout))
 	}
 	return opts
 }
 
+// grpcTLS adapts a gRPC exporter's credentials option to a TLS config.
+func grpcTLS[O any](withCredentials func(credentials.TransportCredentials) O) func(*tls.Config) O {
+	return func(c *tls.Config) O {
+		return withCredentials(credentials.NewTLS(c))
+	}
+}
+
+// MetricOptions returns the OTLP/gRPC metric exporter options. TLS files that
+// fail to load are skipped here and reported by OtelMeterProvider.
+func (cfg OpenTelemetryConfig) MetricOptions() []otlpmetricgrpc.Option {
+	return otlpOptions(cfg.otlpSettings(cfg.OpenTelemetryMetricsEndpoint, ""), otlpOptionFuncs[otlpmetricgrpc.Option]{
+		endpoint:    otlpmetricgrpc.WithEndpoint,
+		endpointURL: otlpmetricgrpc.WithEndpointURL,
+		tls:         grpcTLS(otlpmetricgrpc.WithTLSCredentials),
+		insecure:    otlpmetricgrpc.WithInsecure,
+		headers:     otlpmetricgrpc.WithHeaders,
+		gzip:        otlpmetricgrpc.WithCompressor(gzip.Name),
+		timeout:     otlpmetricgrpc.WithTimeout,
+	})
+}
+
+// MetricHTTPOptions returns the OTLP/HTTP metric exporter options. TLS files
+// that fail to load are skipped here and reported by OtelMeterProvider.
+func (cfg OpenTelemetryConfig) MetricHTTPOptions() []otlpmetrichttp.Option {
+	return otlpOptions(cfg.otlpSettings(cfg.OpenTelemetryMetricsEndpoint, "/v1/metrics"), otlpOptionFuncs[otlpmetrichttp.Option]{
+		endpoint:    otlpmetrichttp.WithEndpoint,
+		endpointURL: otlpmetrichttp.WithEndpointURL,
+		tls:         otlpmetrichttp.WithTLSClientConfig,
+		insecure:    otlpmetrichttp.WithInsecure,
+		headers:     otlpmetrichttp.WithHeaders,
+		gzip:        otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression),
+		timeout:     otlpmetrichttp.WithTimeout,
+	})
+}
+
+// TracerOptions returns the OTLP/gRPC trace exporter options. TLS files that
+// fail to load are skipped here and reported by OtelTraceProvider.
+func (cfg OpenTelemetryConfig) TracerOptions() []otlptracegrpc.Option {
+	return otlpOptions(cfg.otlpSettings(cfg.OpenTelemetryTracesEndpoint, ""), otlpOptionFuncs[otlptracegrpc.Option]{
+		endpoint:    otlptracegrpc.WithEndpoint,
+		endpointURL: otlptracegrpc.WithEndpointURL,
+		tls:         grpcTLS(otlptracegrpc.WithTLSCredentials),
+		insecure:    otlptracegrpc.WithInsecure,
+		headers:     otlptracegrpc.WithHeaders,
+		gzip:        otlptracegrpc.WithCompressor(gzip.Name),
+		timeout:     otlptracegrpc.WithTimeout,
+	})
+}
+
+// TracerHTTPOptions returns the OTLP/HTTP trace exporter options. TLS files
+// that fail to load are skipped here and reported by OtelTraceProvider.
+func (cfg OpenTelemetryConfig) TracerHTTPOptions() []otlptracehttp.Option {
+	return otlpOptions(cfg.otlpSettings(cfg.OpenTelemetryTracesEndpoint, "/v1/traces"), otlpOptionFuncs[otlptracehttp.Option]{
+		endpoint:    otlptracehttp.WithEndpoint,
+		endpointURL: otlptracehttp.WithEndpointURL,
+		tls:         otlptracehttp.WithTLSClientConfig,
+		insecure:    otlptracehttp.WithInsecure,
+		headers:     otlptracehttp.WithHeaders,
+		gzip:        otlptracehttp.WithCompression(otlptracehttp.GzipCompression),
+		timeout:     otlptracehttp.WithTimeout,
+	})
+}
+
+// LogOptions returns the OTLP/gRPC log exporter options. TLS files that fail
+// to load are skipped here and reported by OtelLogProvider.
 func (cfg OpenTelemetryConfig) LogOptions() []otlploggrpc.Option {
-	opts := []otlploggrpc.Option{
-		otlploggrpc.WithEndpoint(cfg.OpenTelemetryEndpoint),
+	return otlpOptions(cfg.otlpSettings(cfg.OpenTelemetryLogsEndpoint, ""), otlpOptionFuncs[otlploggrpc.Option]{
+		endpoint:    otlploggrpc.WithEndpoint,
+		endpointURL: otlploggrpc.WithEndpointURL,
+		tls:         grpcTLS(otlploggrpc.WithTLSCredentials),
+		insecure:    otlploggrpc.WithInsecure,
+		headers:     otlploggrpc.WithHeaders,
+		gzip:        otlploggrpc.WithCompressor(gzip.Name),
+		timeout:     otlploggrpc.WithTimeout,
+	})
+}
+
+// LogHTTPOptions returns the OTLP/HTTP log exporter options. TLS files that
+// fail to load are skipped here and reported by OtelLogProvider.
+func (cfg OpenTelemetryConfig) LogHTTPOptions() []otlploghttp.Option {
+	return otlpOptions(cfg.otlpSettings(cfg.OpenTelemetryLogsEndpoint, "/v1/logs"), otlpOptionFuncs[otlploghttp.Option]{
+		endpoint:    otlploghttp.WithEndpoint,
+		endpointURL: otlploghttp.WithEndpointURL,
+		tls:         otlploghttp.WithTLSClientConfig,
+		insecure:    otlploghttp.WithInsecure,
+		headers:     otlploghttp.WithHeaders,
+		gzip:        otlploghttp.WithCompression(otlploghttp.GzipCompression),
+		timeout:     otlploghttp.WithTimeout,
+	})
+}
+
+// traceExporter creates the trace exporter chosen by the config, or nil for none.
+func (cfg OpenTelemetryConfig) traceExporter(ctx context.Context) (trace.SpanExporter, error) {
+	switch cfg.OpenTelemetryTracesExporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterConsole:
+		return stdouttrace.New()
+	case ExporterOTLP, "":
+		if _, err := cfg.TLSConfig(); err != nil {
+			return nil, err
+		}
+		if cfg.OpenTelemetryProtocol == ProtocolHTTP {
+			return otlptracehttp.New(ctx, cfg.TracerHTTPOptions()...)
+		}
+		return otlptracegrpc.New(ctx, cfg.TracerOptions()...)
+	default:
+		return nil, fmt.Errorf("unsupported traces exporter: %s", cfg.OpenTelemetryTracesExporter)
 	}
-	if cfg.OpenTelemetryInsecure {
-		opts = append(opts, otlploggrpc.WithInsecure())
+}
+
+// metricReaders creates a reader for every metrics exporter in the config.
+// Push exporters are read periodically, while the prometheus exporter is
+// registered with the default Prometheus registry for promhttp to serve.
+func (cfg OpenTelemetryConfig) metricReaders(ctx context.Context) ([]metric.Reader, error) {
+	exporters := cfg.OpenTelemetryMetricsExporter
+	if len(exporters) == 0 {
+		exporters = []string{ExporterOTLP}
+	}
+	var readers []metric.Reader
+	for _, exporter := range exporters {
+		switch strings.TrimSpace(exporter) {
+		case ExporterNone:
+		case ExporterConsole:
+			exp, err := stdoutmetric.New()
+			if err != nil {
+				return nil, err
+			}
+			readers = append(readers, metric.NewPeriodicReader(exp))
+		case ExporterPrometheus:
+			// Names are always translated the Prometheus way (dots to
+			// underscores, unit and _total suffixes), independent of the
+			// global name validation scheme, so scraped and pushed metrics match.
+			exp, err := prometheus.New(
+				prometheus.WithTranslationStrategy(otlptranslator.UnderscoreEscapingWithSuffixes),
+			)
+			if err != nil {
+				return nil, err
+			}
+			readers = append(readers, exp)
+		case ExporterOTLP:
+			if _, err := cfg.TLSConfig(); err != nil {
+				return nil, err
+			}
+			var exp metric.Exporter
+			var err error
+			if cfg.OpenTelemetryProtocol == ProtocolHTTP {
+				exp, err = otlpmetrichttp.New(ctx, cfg.MetricHTTPOptions()...)
+			} else {
+				exp, err = otlpmetricgrpc.New(ctx, cfg.MetricOptions()...)
+			}
+			if err != nil {
+				return nil, err
+			}
+			readers = append(readers, metric.NewPeriodicReader(exp))
+		default:
+			return nil, fmt.Errorf("unsupported metrics exporter: %s", exporter)
+		}
+	}
+	return readers, nil
+}
+
+// logExporter creates the log exporter chosen by the config, or nil for none.
+func (cfg OpenTelemetryConfig) logExporter(ctx context.Context) (log.Exporter, error) {
+	switch cfg.OpenTelemetryLogsExporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterConsole:
+		return stdoutlog.New()
+	case ExporterOTLP, "":
+		if _, err := cfg.TLSConfig(); err != nil {
+			return nil, err
+		}
+		if cfg.OpenTelemetryProtocol == ProtocolHTTP {
+			return otlploghttp.New(ctx, cfg.LogHTTPOptions()...)
+		}
+		return otlploggrpc.New(ctx, cfg.LogOptions()...)
+	default:
+		return nil, fmt.Errorf("unsupported logs exporter: %s", cfg.OpenTelemetryLogsExporter)
 	}
-	return opts
 }
 
 // OtelResource creates a otel resource from an app config.
@@ -88,17 +474,24 @@ func OtelResource(ctx context.Context, cfg AppConfig, attributes ...attribute.Ke
 // or end up in a situation where every service configures open telemetry differently.
 func OtelTraceProvider(ctx context.Context, cfg OpenTelemetryConfig, r *resource.Resource,
 ) (*trace.TracerProvider, error) {
-	traceExporter, err := otlptracegrpc.New(ctx, cfg.TracerOptions()...)
+	traceExporter, err := cfg.traceExporter(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
 	}
 
-	tp := trace.NewTracerProvider(
-		trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(cfg.OpenTelemetrySampleRate))),
+	opts := []trace.TracerProviderOption{
+		trace.WithSampler(cfg.Sampler()),
 		trace.WithResource(r),
 		trace.WithSpanProcessor(baggagecopy.NewSpanProcessor(baggagecopy.AllowAllMembers)),
-		trace.WithSpanProcessor(trace.NewBatchSpanProcessor(traceExporter)),
-	)
+	}
+	if traceExporter != nil {
+		var processor trace.SpanProcessor = trace.NewBatchSpanProcessor(traceExporter)
+		if cfg.OpenTelemetrySampleErrors {
+			processor = TailSamplingProcessor(processor, KeepErrors)
+		}
+		opts = append(opts, trace.WithSpanProcessor(processor))
+	}
+	tp := trace.NewTracerProvider(opts...)
 	otel.SetTracerProvider(tp)
 
 	return tp, nil
@@ -110,15 +503,16 @@ func OtelTraceProvider(ctx context.Context, cfg OpenTelemetryConfig, r *resource
 // or end up in a situation where every service configures open telemetry differently.
 func OtelMeterProvider(ctx context.Context, cfg OpenTelemetryConfig, r *resource.Resource,
 ) (*metric.MeterProvider, error) {
-	metricExporter, err := otlpmetricgrpc.New(ctx, cfg.MetricOptions()...)
+	readers, err := cfg.metricReaders(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metric exporter: %w", err)
 	}
 
-	mp := metric.NewMeterProvider(
-		metric.WithResource(r),
-		metric.WithReader(metric.NewPeriodicReader(metricExporter)),
-	)
+	opts := []metric.Option{metric.WithResource(r)}
+	for _, reader := range readers {
+		opts = append(opts, metric.WithReader(reader))
+	}
+	mp := metric.NewMeterProvider(opts...)
 	otel.SetMeterProvider(mp)
 
 	if err := runtime.Start(); err != nil {
@@ -132,10 +526,13 @@ func OtelMeterProvider(ctx context.Context, cfg OpenTelemetryConfig, r *resource
 }
 
 func OtelLogProvider(ctx context.Context, cfg OpenTelemetryConfig, r *resource.Resource) (*log.LoggerProvider, error) {
-	logExporter, err := otlploggrpc.New(ctx, cfg.LogOptions()...)
+	logExporter, err := cfg.logExporter(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create log exporter: %w", err)
 	}
+	if logExporter == nil {
+		return log.NewLoggerProvider(log.WithResource(r)), nil
+	}
 
 	provider := log.NewLoggerProvider(
 		log.WithResource(r),
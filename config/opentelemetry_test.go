@@ -1,17 +1,24 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/kelseyhightower/envconfig"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"gopkg.in/yaml.v3"
 )
 
 func TestOpenTelemetryConfig_MetricOptions(t *testing.T) {
@@ -38,6 +45,21 @@ func TestOpenTelemetryConfig_MetricOptions(t *testing.T) {
 				otlpmetricgrpc.WithInsecure(),
 			},
 		},
+		{
+			name: "full config",
+			cfg: OpenTelemetryConfig{
+				OpenTelemetryEndpoint:    "https://collector:4317",
+				OpenTelemetryHeaders:     Headers{"authorization": "test-token"},
+				OpenTelemetryCompression: "gzip",
+				OpenTelemetryTimeout:     Milliseconds(5 * time.Second),
+			},
+			want: []otlpmetricgrpc.Option{
+				otlpmetricgrpc.WithEndpointURL("https://collector:4317"),
+				otlpmetricgrpc.WithHeaders(nil),
+				otlpmetricgrpc.WithCompressor("gzip"),
+				otlpmetricgrpc.WithTimeout(5 * time.Second),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -79,6 +101,21 @@ func TestOpenTelemetryConfig_TracerOptions(t *testing.T) {
 				otlptracegrpc.WithInsecure(),
 			},
 		},
+		{
+			name: "full config",
+			cfg: OpenTelemetryConfig{
+				OpenTelemetryEndpoint:    "https://collector:4317",
+				OpenTelemetryHeaders:     Headers{"authorization": "test-token"},
+				OpenTelemetryCompression: "gzip",
+				OpenTelemetryTimeout:     Milliseconds(5 * time.Second),
+			},
+			want: []otlptracegrpc.Option{
+				otlptracegrpc.WithEndpointURL("https://collector:4317"),
+				otlptracegrpc.WithHeaders(nil),
+				otlptracegrpc.WithCompressor("gzip"),
+				otlptracegrpc.WithTimeout(5 * time.Second),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -120,6 +157,21 @@ func TestOpenTelemetryConfig_LogOptions(t *testing.T) {
 				otlploggrpc.WithInsecure(),
 			},
 		},
+		{
+			name: "full config",
+			cfg: OpenTelemetryConfig{
+				OpenTelemetryEndpoint:    "https://collector:4317",
+				OpenTelemetryHeaders:     Headers{"authorization": "test-token"},
+				OpenTelemetryCompression: "gzip",
+				OpenTelemetryTimeout:     Milliseconds(5 * time.Second),
+			},
+			want: []otlploggrpc.Option{
+				otlploggrpc.WithEndpointURL("https://collector:4317"),
+				otlploggrpc.WithHeaders(nil),
+				otlploggrpc.WithCompressor("gzip"),
+				otlploggrpc.WithTimeout(5 * time.Second),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -137,6 +189,194 @@ func TestOpenTelemetryConfig_LogOptions(t *testing.T) {
 	}
 }
 
+func TestOpenTelemetryConfig_HTTPOptions(t *testing.T) {
+	cfg := OpenTelemetryConfig{
+		OpenTelemetryEndpoint:       "http://collector:4318",
+		OpenTelemetryTracesEndpoint: "https://traces:4318/custom",
+		OpenTelemetryProtocol:       ProtocolHTTP,
+		OpenTelemetryInsecure:       true,
+		OpenTelemetryCompression:    "gzip",
+	}
+	if got := cfg.TracerHTTPOptions(); len(got) != 3 {
+		t.Errorf("TracerHTTPOptions() = %v, want 3 options", got)
+	}
+	if got := cfg.MetricHTTPOptions(); len(got) != 3 {
+		t.Errorf("MetricHTTPOptions() = %v, want 3 options", got)
+	}
+	if got := cfg.LogHTTPOptions(); len(got) != 3 {
+		t.Errorf("LogHTTPOptions() = %v, want 3 options", got)
+	}
+}
+
+func TestOpenTelemetryConfig_otlpSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  OpenTelemetryConfig
+		want otlpSettings
+	}{
+		{
+			name: "defaults",
+			cfg:  OpenTelemetryConfig{OpenTelemetryInsecure: true},
+			want: otlpSettings{endpoint: "localhost:4317", insecure: true},
+		},
+		{
+			name: "all settings",
+			cfg: OpenTelemetryConfig{
+				OpenTelemetryEndpoint:    "https://collector:4317",
+				OpenTelemetryHeaders:     Headers{"test-key": "test-value"},
+				OpenTelemetryCompression: "gzip",
+				OpenTelemetryTimeout:     Milliseconds(time.Second),
+			},
+			want: otlpSettings{
+				endpoint: "https://collector:4317",
+				url:      true,
+				headers:  map[string]string{"test-key": "test-value"},
+				gzip:     true,
+				timeout:  time.Second,
+			},
+		},
+		{
+			name: "unreadable certificate falls back to insecure",
+			cfg:  OpenTelemetryConfig{OpenTelemetryInsecure: true, OpenTelemetryCertificate: "testdata/missing.pem"},
+			want: otlpSettings{endpoint: "localhost:4317", insecure: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.otlpSettings("", ""); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OpenTelemetryConfig.otlpSettings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenTelemetryConfig_endpoint(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            OpenTelemetryConfig
+		signalEndpoint string
+		want           string
+		wantURL        bool
+	}{
+		{
+			name: "host and port",
+			cfg:  OpenTelemetryConfig{OpenTelemetryEndpoint: "localhost:4317"},
+			want: "localhost:4317",
+		},
+		{
+			name: "default grpc",
+			cfg:  OpenTelemetryConfig{},
+			want: "localhost:4317",
+		},
+		{
+			name: "default http",
+			cfg:  OpenTelemetryConfig{OpenTelemetryProtocol: ProtocolHTTP},
+			want: "localhost:4318",
+		},
+		{
+			name:    "grpc url",
+			cfg:     OpenTelemetryConfig{OpenTelemetryEndpoint: "https://collector:4317"},
+			want:    "https://collector:4317",
+			wantURL: true,
+		},
+		{
+			name:    "http url with signal path",
+			cfg:     OpenTelemetryConfig{OpenTelemetryEndpoint: "https://collector:4318/", OpenTelemetryProtocol: ProtocolHTTP},
+			want:    "https://collector:4318/v1/traces",
+			wantURL: true,
+		},
+		{
+			name:           "signal endpoint used as is",
+			cfg:            OpenTelemetryConfig{OpenTelemetryEndpoint: "https://collector:4318", OpenTelemetryProtocol: ProtocolHTTP},
+			signalEndpoint: "https://traces:4318/custom",
+			want:           "https://traces:4318/custom",
+			wantURL:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotURL := tt.cfg.endpoint(tt.signalEndpoint, "/v1/traces")
+			if got != tt.want || gotURL != tt.wantURL {
+				t.Errorf("endpoint() = %v, %v, want %v, %v", got, gotURL, tt.want, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestHeaders_Decode(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Headers
+		wantErr bool
+	}{
+		{
+			name:  "headers",
+			value: "authorization=Bearer%20test-token, x-tenant=test-tenant",
+			want:  Headers{"authorization": "Bearer test-token", "x-tenant": "test-tenant"},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  Headers{},
+		},
+		{
+			name:    "missing value",
+			value:   "authorization",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Headers
+			if err := got.Decode(tt.value); (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenTelemetryConfig_TLSConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     OpenTelemetryConfig
+		wantNil bool
+		wantErr bool
+	}{
+		{
+			name:    "no tls",
+			cfg:     OpenTelemetryConfig{},
+			wantNil: true,
+		},
+		{
+			name:    "missing certificate",
+			cfg:     OpenTelemetryConfig{OpenTelemetryCertificate: "testdata/nonexistent.pem"},
+			wantNil: true,
+			wantErr: true,
+		},
+		{
+			name:    "invalid certificate",
+			cfg:     OpenTelemetryConfig{OpenTelemetryCertificate: "testdata/global.json"},
+			wantNil: true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.TLSConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("TLSConfig() = %v, wantNil %v", got, tt.wantNil)
+			}
+		})
+	}
+}
+
 func Test_otelResource(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -230,6 +470,31 @@ func TestOtelTraceProvider(t *testing.T) {
 			cfg:     OpenTelemetryConfig{},
 			wantErr: false,
 		},
+		{
+			name: "http protocol",
+			cfg: OpenTelemetryConfig{
+				OpenTelemetryEndpoint: "localhost:4318",
+				OpenTelemetryProtocol: ProtocolHTTP,
+			},
+		},
+		{
+			name: "console exporter",
+			cfg:  OpenTelemetryConfig{OpenTelemetryTracesExporter: ExporterConsole},
+		},
+		{
+			name: "none exporter",
+			cfg:  OpenTelemetryConfig{OpenTelemetryTracesExporter: ExporterNone},
+		},
+		{
+			name:    "unsupported exporter",
+			cfg:     OpenTelemetryConfig{OpenTelemetryTracesExporter: "test-exporter"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid certificate",
+			cfg:     OpenTelemetryConfig{OpenTelemetryCertificate: "testdata/global.json"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -238,6 +503,9 @@ func TestOtelTraceProvider(t *testing.T) {
 				t.Errorf("OtelTraceProvider() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantErr {
+				return
+			}
 			if got == nil {
 				t.Errorf("OtelTraceProvider() = %v, want non-nil", got)
 				return
@@ -268,6 +536,31 @@ func TestOtelMeterProvider(t *testing.T) {
 			cfg:     OpenTelemetryConfig{},
 			wantErr: false,
 		},
+		{
+			name: "http protocol",
+			cfg: OpenTelemetryConfig{
+				OpenTelemetryEndpoint: "localhost:4318",
+				OpenTelemetryProtocol: ProtocolHTTP,
+			},
+		},
+		{
+			name: "console exporter",
+			cfg:  OpenTelemetryConfig{OpenTelemetryMetricsExporter: []string{ExporterConsole}},
+		},
+		{
+			name: "none exporter",
+			cfg:  OpenTelemetryConfig{OpenTelemetryMetricsExporter: []string{ExporterNone}},
+		},
+		{
+			name:    "unsupported exporter",
+			cfg:     OpenTelemetryConfig{OpenTelemetryMetricsExporter: []string{"test-exporter"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid certificate",
+			cfg:     OpenTelemetryConfig{OpenTelemetryCertificate: "testdata/global.json"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -277,6 +570,9 @@ func TestOtelMeterProvider(t *testing.T) {
 				t.Errorf("OtelMeterProvider() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantErr {
+				return
+			}
 			if got == nil {
 				t.Errorf("OtelMeterProvider() = %v, want non-nil", got)
 				return
@@ -307,6 +603,31 @@ func TestOtelLogProvider(t *testing.T) {
 			cfg:     OpenTelemetryConfig{},
 			wantErr: false,
 		},
+		{
+			name: "http protocol",
+			cfg: OpenTelemetryConfig{
+				OpenTelemetryEndpoint: "localhost:4318",
+				OpenTelemetryProtocol: ProtocolHTTP,
+			},
+		},
+		{
+			name: "console exporter",
+			cfg:  OpenTelemetryConfig{OpenTelemetryLogsExporter: ExporterConsole},
+		},
+		{
+			name: "none exporter",
+			cfg:  OpenTelemetryConfig{OpenTelemetryLogsExporter: ExporterNone},
+		},
+		{
+			name:    "unsupported exporter",
+			cfg:     OpenTelemetryConfig{OpenTelemetryLogsExporter: "test-exporter"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid certificate",
+			cfg:     OpenTelemetryConfig{OpenTelemetryCertificate: "testdata/global.json"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -315,9 +636,79 @@ func TestOtelLogProvider(t *testing.T) {
 				t.Errorf("OtelLogProvider() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantErr {
+				return
+			}
 			if got == nil {
 				t.Errorf("OtelLogProvider() = %v, want non-nil", got)
 			}
 		})
 	}
 }
+
+func TestOtelMeterProvider_Prometheus(t *testing.T) {
+	cfg := OpenTelemetryConfig{OpenTelemetryMetricsExporter: []string{ExporterNone, ExporterPrometheus}}
+	mp, err := OtelMeterProvider(t.Context(), cfg, resource.Empty())
+	if err != nil {
+		t.Fatalf("OtelMeterProvider() error = %v", err)
+	}
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+
+	counter, err := mp.Meter("test").Int64Counter("test.requests", metric.WithUnit("{request}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.Add(t.Context(), 1)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() == "test_requests_total" {
+			return
+		}
+	}
+	t.Error("test_requests_total not found in the Prometheus registry")
+}
+
+func TestMilliseconds(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "milliseconds", value: "10000", want: 10 * time.Second},
+		{name: "zero", value: "0", want: 0},
+		{name: "duration", value: "10s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", tt.value)
+			var cfg OpenTelemetryConfig
+			err := envconfig.Process("", &cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("envconfig.Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.OpenTelemetryTimeout.Duration() != tt.want {
+				t.Errorf("OpenTelemetryTimeout = %v, want %v", cfg.OpenTelemetryTimeout.Duration(), tt.want)
+			}
+		})
+	}
+
+	for _, data := range []string{`{"timeout": 2500}`, `{"timeout": "2500"}`} {
+		var v struct {
+			Timeout Milliseconds `json:"timeout"`
+		}
+		if err := json.Unmarshal([]byte(data), &v); err != nil || v.Timeout.Duration() != 2500*time.Millisecond {
+			t.Errorf("json.Unmarshal(%s) = %v, %v, want 2.5s", data, v.Timeout.Duration(), err)
+		}
+	}
+	var v struct {
+		Timeout Milliseconds `yaml:"timeout"`
+	}
+	if err := yaml.Unmarshal([]byte("timeout: 2500"), &v); err != nil || v.Timeout.Duration() != 2500*time.Millisecond {
+		t.Errorf("yaml.Unmarshal() = %v, %v, want 2.5s", v.Timeout.Duration(), err)
+	}
+}
@@ -1,28 +1,36 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/XSAM/otelsql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jmoiron/sqlx"
 	"github.com/signalfx/splunk-otel-go/instrumentation/database/sql/splunksql"
 	"github.com/signalfx/splunk-otel-go/instrumentation/github.com/jmoiron/sqlx/splunksqlx"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type PostgresConfig struct {
-	Password        string        `envconfig:"POSTGRES_PASSWORD"`
-	User            string        `envconfig:"POSTGRES_USER"     default:"postgres"`
-	Port            int           `envconfig:"POSTGRES_PORT"     default:"5432"`
-	Database        string        `envconfig:"POSTGRES_DB"       default:"postgres"`
-	Host            string        `envconfig:"POSTGRES_HOST"     default:"localhost"`
-	SSLMode         string        `envconfig:"POSTGRES_SSLMODE"  default:"require"`
-	MaxConns        int           `envconfig:"POSTGRES_MAX_CONNS"`
-	MaxConnDuration time.Duration `envconfig:"POSTGRES_MAX_CONN_DURATION"`
-	MaxIdleConns    int           `envconfig:"POSTGRES_MAX_IDLE_CONNS"`
-	MaxIdleDuration time.Duration `envconfig:"POSTGRES_MAX_IDLE_DURATION"`
+	Password        string        `envconfig:"POSTGRES_PASSWORD" secret:"true"`
+	User            string        `envconfig:"POSTGRES_USER"     default:"postgres"  validate:"required"`
+	Port            int           `envconfig:"POSTGRES_PORT"     default:"5432"      validate:"min=1,max=65535"`
+	Database        string        `envconfig:"POSTGRES_DB"       default:"postgres"  validate:"required"`
+	Host            string        `envconfig:"POSTGRES_HOST"     default:"localhost" validate:"required"`
+	SSLMode         string        `envconfig:"POSTGRES_SSLMODE"  default:"require"   validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	MaxConns        int           `envconfig:"POSTGRES_MAX_CONNS"                    validate:"min=0"`
+	MaxConnDuration time.Duration `envconfig:"POSTGRES_MAX_CONN_DURATION"            validate:"min=0"`
+	MaxIdleConns    int           `envconfig:"POSTGRES_MAX_IDLE_CONNS"               validate:"min=0"`
+	MaxIdleDuration time.Duration `envconfig:"POSTGRES_MAX_IDLE_DURATION"            validate:"min=0"`
+	ConnectTimeout  time.Duration `envconfig:"POSTGRES_CONNECT_TIMEOUT" default:"30s" validate:"min=0" help:"how long to retry the initial connection, 0 to try once"`
 }
 
 func (p PostgresConfig) ConnectionString() string {
@@ -35,13 +43,20 @@ func (p PostgresConfig) ConnectionString() string {
 		p.SSLMode)
 }
 
+// NewPostgresClient connects to Postgres, retrying with exponential backoff
+// for up to ConnectTimeout while the database is unreachable.
 func NewPostgresClient(cfg PostgresConfig) (*sqlx.DB, error) {
 	postgresAttrs := []attribute.KeyValue{attribute.String("service.name", "postgres")}
-	db, err := splunksqlx.Connect("postgres", cfg.ConnectionString(),
-		splunksql.WithAttributes(postgresAttrs),
-	)
+	var db *sqlx.DB
+	err := connectWithRetry(context.Background(), "postgres", cfg.ConnectTimeout, func(ctx context.Context) error {
+		var err error
+		db, err = splunksqlx.ConnectContext(ctx, "postgres", cfg.ConnectionString(),
+			splunksql.WithAttributes(postgresAttrs),
+		)
+		return err
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
 	}
 
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
@@ -55,3 +70,65 @@ func NewPostgresClient(cfg PostgresConfig) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// PoolConfig returns the pgxpool config for the Postgres config, traced with
+// OpenTelemetry. MaxIdleConns has no pgxpool counterpart and is ignored.
+func (p PostgresConfig) PoolConfig() (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(p.ConnectionString())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse postgres config: %w", err)
+	}
+	if p.MaxConns > 0 {
+		poolCfg.MaxConns = int32(min(p.MaxConns, math.MaxInt32)) // nolint:gosec
+	}
+	if p.MaxConnDuration > 0 {
+		poolCfg.MaxConnLifetime = p.MaxConnDuration
+	}
+	if p.MaxIdleDuration > 0 {
+		poolCfg.MaxConnIdleTime = p.MaxIdleDuration
+	}
+	poolCfg.ConnConfig.Tracer = pgxTracer{tracer: otel.Tracer("github.com/jesse0michael/pkg/config")}
+	return poolCfg, nil
+}
+
+// NewPostgresPool creates a pgx connection pool for Postgres, retrying with
+// exponential backoff for up to ConnectTimeout while the database is
+// unreachable. Queries are traced with OpenTelemetry. Pass
+// PingFunc(pool.Ping) to NewDBHealthChecker to check its health.
+func NewPostgresPool(ctx context.Context, cfg PostgresConfig) (*pgxpool.Pool, error) {
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		return nil, err
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres pool: %w", err)
+	}
+	if err := connectWithRetry(ctx, "postgres", cfg.ConnectTimeout, pool.Ping); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	return pool, nil
+}
+
+// pgxTracer starts a client span for each pgx query.
+type pgxTracer struct {
+	tracer trace.Tracer
+}
+
+func (t pgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = t.tracer.Start(ctx, "postgres.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBStatement(data.SQL)),
+	)
+	return ctx
+}
+
+func (pgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
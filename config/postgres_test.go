@@ -3,6 +3,7 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestPostgresConfig_ConnectionString(t *testing.T) {
@@ -20,3 +21,53 @@ func TestPostgresConfig_ConnectionString(t *testing.T) {
 		t.Errorf("PostgresConfig.ConnectionString() = %v, want %v", got, want)
 	}
 }
+
+func TestPostgresConfig_PoolConfig(t *testing.T) {
+	config := PostgresConfig{
+		Database:        "test-db",
+		Port:            5432,
+		User:            "test-user",
+		Host:            "localhost",
+		Password:        "test-password",
+		SSLMode:         "disable",
+		MaxConns:        10,
+		MaxConnDuration: time.Hour,
+		MaxIdleDuration: time.Minute,
+	}
+
+	got, err := config.PoolConfig()
+	if err != nil {
+		t.Fatalf("PostgresConfig.PoolConfig() error = %v", err)
+	}
+	if got.ConnConfig.Database != "test-db" || got.ConnConfig.User != "test-user" || got.ConnConfig.Password != "test-password" {
+		t.Errorf("PostgresConfig.PoolConfig() conn config = %+v", got.ConnConfig.Config)
+	}
+	if got.MaxConns != 10 {
+		t.Errorf("PostgresConfig.PoolConfig() MaxConns = %d, want 10", got.MaxConns)
+	}
+	if got.MaxConnLifetime != time.Hour {
+		t.Errorf("PostgresConfig.PoolConfig() MaxConnLifetime = %v, want %v", got.MaxConnLifetime, time.Hour)
+	}
+	if got.MaxConnIdleTime != time.Minute {
+		t.Errorf("PostgresConfig.PoolConfig() MaxConnIdleTime = %v, want %v", got.MaxConnIdleTime, time.Minute)
+	}
+	if _, ok := got.ConnConfig.Tracer.(pgxTracer); !ok {
+		t.Errorf("PostgresConfig.PoolConfig() Tracer = %T, want pgxTracer", got.ConnConfig.Tracer)
+	}
+}
+
+func TestNewPostgresPool(t *testing.T) {
+	config := PostgresConfig{
+		Database: "test-db",
+		Port:     1,
+		User:     "test-user",
+		Host:     "127.0.0.1",
+		SSLMode:  "disable",
+	}
+
+	pool, err := NewPostgresPool(t.Context(), config)
+	if err == nil {
+		pool.Close()
+		t.Fatal("NewPostgresPool() expected an error for an unreachable database")
+	}
+}
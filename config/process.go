@@ -1,21 +1,23 @@
 package config
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/alexflint/go-arg"
-	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
-	"gopkg.in/yaml.v3"
 )
 
 type options struct {
-	prefix string
-	files  []string
+	prefix     string
+	files      []fileSource
+	overlay    bool
+	provenance *Provenance
+	resolvers  map[string]SecretResolver
 }
 
 // Option configures the New function.
@@ -29,11 +31,31 @@ func WithPrefix(prefix string) Option {
 }
 
 // WithFile adds a config file to be loaded. Files are applied in order,
-// so later files override earlier ones. Supports JSON and YAML.
+// so later files override earlier ones. Supports JSON, YAML, TOML, HCL and
+// .env files, which set fields by their env var names.
 // Files that don't exist are silently skipped.
 func WithFile(path string) Option {
 	return func(o *options) {
-		o.files = append(o.files, path)
+		o.files = append(o.files, fileSource{path: path})
+	}
+}
+
+// WithDir adds every supported config file in a directory, applied in
+// lexical order in place of a single WithFile. Subdirectories are not read,
+// and a directory that doesn't exist is silently skipped.
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.files = append(o.files, fileSource{path: dir, dir: true})
+	}
+}
+
+// WithEnvironmentOverlay applies an environment overlay after each WithFile
+// file, e.g. config.production.yaml after config.yaml. The environment is
+// the AppConfig.Environment of the config, or the ENVIRONMENT env var when
+// the config doesn't embed AppConfig.
+func WithEnvironmentOverlay() Option {
+	return func(o *options) {
+		o.overlay = true
 	}
 }
 
@@ -43,36 +65,114 @@ type Initializer interface {
 	Init()
 }
 
+// Validator can be implemented by config structs to reject invalid values
+// once all sources have been applied.
+type Validator interface {
+	Validate() error
+}
+
 // New creates a config of type T by layering sources in order:
 // init < struct tag defaults + env vars < files (in order) < CLI args.
+// The merged config is then checked against its validate struct tags
+// (go-playground/validator) and its Validate method, if T implements Validator.
+// Fields tagged secret:"true" that hold a reference such as file:///run/secrets/db
+// or env://OTHER_VAR are resolved before validation; see WithSecretResolver.
+// Use WithProvenance to record which source set each field.
 func New[T any](opts ...Option) (T, error) {
-	var o options
+	return load[T](context.Background(), newOptions(opts))
+}
+
+func newOptions(opts []Option) options {
+	o := options{resolvers: defaultResolvers()}
 	for _, opt := range opts {
 		opt(&o)
 	}
+	return o
+}
 
+func load[T any](ctx context.Context, o options) (T, error) {
 	var cfg T
+	var rec *recorder
+	if o.provenance != nil && reflect.TypeFor[T]().Kind() == reflect.Struct {
+		rec = newRecorder(&cfg, o.prefix)
+	}
+
 	if d, ok := any(&cfg).(Initializer); ok {
 		d.Init()
 	}
+	rec.record(func(field) Origin { return Origin{Source: SourceInit} })
+
+	// Help is checked first, so it's shown even when required env vars are
+	// missing or invalid.
+	if helpRequested(os.Args[1:]) {
+		return cfg, fmt.Errorf("failed to parse CLI args: %w", ErrHelp)
+	}
 
 	if err := envconfig.Process(o.prefix, &cfg); err != nil {
 		return cfg, fmt.Errorf("failed to process env config: %w", err)
 	}
+	rec.recordEnv()
 
-	for _, path := range o.files {
-		if err := loadFile(path, &cfg); err != nil {
-			return cfg, fmt.Errorf("failed to load config file %s: %w", path, err)
+	loadPath := func(path string) error {
+		if err := loadFile(path, &cfg, o.prefix); err != nil {
+			return fmt.Errorf("failed to load config file %s: %w", path, err)
+		}
+		rec.record(func(field) Origin { return Origin{Source: SourceFile, Key: path} })
+		return nil
+	}
+	for _, src := range o.files {
+		paths, err := src.paths()
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read config dir %s: %w", src.path, err)
+		}
+		for _, path := range paths {
+			if err := loadPath(path); err != nil {
+				return cfg, err
+			}
+		}
+		// The environment is read after the base file, which may set it.
+		if o.overlay && !src.dir {
+			if env := environment(&cfg, o.prefix); env != "" {
+				if err := loadPath(overlayPath(src.path, env)); err != nil {
+					return cfg, err
+				}
+			}
 		}
 	}
 
 	if err := parseArgs(os.Args[1:], &cfg); err != nil {
 		return cfg, fmt.Errorf("failed to parse CLI args: %w", err)
 	}
+	rec.record(func(f field) Origin { return Origin{Source: SourceArg, Key: f.Flag} })
+
+	if err := resolveSecrets(ctx, &cfg, o.resolvers); err != nil {
+		return cfg, err
+	}
+
+	if err := validateConfig(&cfg, o.prefix); err != nil {
+		return cfg, fmt.Errorf("invalid config: %w", err)
+	}
 
+	if rec != nil {
+		o.provenance.set(rec.origins)
+	}
 	return cfg, nil
 }
 
+// helpRequested reports whether args ask for help the way go-arg reads them:
+// -h or --help before a "--" terminator.
+func helpRequested(args []string) bool {
+	for _, a := range args {
+		if a == "--" {
+			return false
+		}
+		if a == "-h" || a == "--help" {
+			return true
+		}
+	}
+	return false
+}
+
 func parseArgs(args []string, cfg any) error {
 	p, err := arg.NewParser(arg.Config{IgnoreEnv: true, IgnoreDefault: true}, cfg)
 	if err != nil {
@@ -84,26 +184,6 @@ func parseArgs(args []string, cfg any) error {
 	return nil
 }
 
-func loadFile(path string, cfg any) error {
-	data, err := os.ReadFile(filepath.Clean(path))
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
-
-	ext := filepath.Ext(path)
-	switch ext {
-	case ".json":
-		return json.Unmarshal(data, cfg)
-	case ".yaml", ".yml":
-		return yaml.Unmarshal(data, cfg)
-	default:
-		return fmt.Errorf("unsupported config file format: %s", ext)
-	}
-}
-
 // Process loads environment variables from .env files in the specified directory
 // and then processes the environment variables into the provided configuration struct
 func Process(envDir string, cfg interface{}) error {
@@ -114,23 +194,37 @@ func Process(envDir string, cfg interface{}) error {
 	return envconfig.Process("", cfg)
 }
 
-// LoadEnv loads environment variables from .env files in the specified directory
+// LoadEnv loads environment variables from .env files in the specified
+// directory, in lexical order, the way WithDir reads them. Variables that are
+// already set are left unchanged.
 func LoadEnv(envDir string) error {
-	if envDir != "" {
-		files, err := os.ReadDir(envDir)
+	if envDir == "" {
+		return nil
+	}
+	if _, err := os.Stat(envDir); err != nil {
+		return err
+	}
+	paths, err := fileSource{path: envDir, dir: true}.paths()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if filepath.Ext(path) != ".env" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Clean(path))
 		if err != nil {
 			return err
 		}
-		for i := range files {
-			f := files[i]
-
-			fullFilePath := filepath.Join(envDir, f.Name())
-			if f.IsDir() || !strings.HasSuffix(f.Name(), ".env") {
-				continue
-			}
-
-			if err := godotenv.Load(fullFilePath); err != nil {
-				return err
+		vars, err := parseEnvFile(data)
+		if err != nil {
+			return err
+		}
+		for k, v := range vars {
+			if _, ok := os.LookupEnv(k); !ok {
+				if err := os.Setenv(k, v); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -454,6 +454,113 @@ func TestNew(t *testing.T) {
 				Secret:      "shh",
 			},
 		},
+		{
+			name:     "toml file",
+			envSetup: func(t *testing.T) {},
+			opts:     []Option{WithFile("testdata/global.json"), WithFile("testdata/local.toml")},
+			want: testNewConfig{
+				Environment: "local",
+				Host:        "toml-host",
+				Port:        9090,
+				Rate:        2.5,
+				Timeout:     30 * time.Second,
+				Secret:      "shh",
+				Items:       []string{"toml-item-1"},
+				Tags:        map[string]string{"source": "toml", "tier": "free"},
+			},
+		},
+		{
+			name:     "hcl file",
+			envSetup: func(t *testing.T) {},
+			opts:     []Option{WithFile("testdata/local.hcl")},
+			want: testNewConfig{
+				Environment: "local",
+				Host:        "hcl-host",
+				Port:        8080,
+				Rate:        1.5,
+				Timeout:     30 * time.Second,
+				Secret:      "shh",
+				Items:       []string{"hcl-item-1"},
+				Tags:        map[string]string{"source": "hcl"},
+			},
+		},
+		{
+			name: "env file overrides env vars",
+			envSetup: func(t *testing.T) {
+				t.Setenv("HOST", "test-host")
+				t.Setenv("DEBUG", "true")
+			},
+			opts: []Option{WithFile("testdata/local.env")},
+			want: testNewConfig{
+				Environment: "development",
+				Host:        "env-file-host",
+				Port:        7070,
+				Debug:       true,
+				Rate:        1.5,
+				Timeout:     2 * time.Minute,
+				Secret:      "shh",
+				Items:       []string{"env-file-item-1", "env-file-item-2"},
+				Tags:        map[string]string{"source": "env-file"},
+			},
+		},
+		{
+			name:     "dir files applied in lexical order",
+			envSetup: func(t *testing.T) {},
+			opts:     []Option{WithDir("testdata/conf.d")},
+			want: testNewConfig{
+				Environment: "local",
+				Host:        "local-host",
+				Port:        9090,
+				Rate:        2.5,
+				Timeout:     30 * time.Second,
+				Secret:      "shh",
+				Items:       []string{"local-item-1"},
+				Tags:        map[string]string{"source": "local", "tier": "free"},
+			},
+		},
+		{
+			name:     "missing dir skipped",
+			envSetup: func(t *testing.T) {},
+			opts:     []Option{WithDir("testdata/nonexistent")},
+			want: testNewConfig{
+				Environment: "development",
+				Host:        "localhost",
+				Port:        8080,
+				Rate:        1.5,
+				Timeout:     30 * time.Second,
+				Secret:      "shh",
+			},
+		},
+		{
+			name: "environment overlay applied after base file",
+			envSetup: func(t *testing.T) {
+				t.Setenv("ENVIRONMENT", "staging")
+			},
+			opts: []Option{WithFile("testdata/overlay/config.yaml"), WithEnvironmentOverlay()},
+			want: testNewConfig{
+				Environment: "staging",
+				Host:        "staging-host",
+				Port:        7000,
+				Rate:        1.5,
+				Timeout:     30 * time.Second,
+				Secret:      "shh",
+			},
+		},
+		{
+			name: "missing environment overlay skipped",
+			envSetup: func(t *testing.T) {
+				t.Setenv("ENVIRONMENT", "production")
+			},
+			opts: []Option{WithFile("testdata/overlay/config.yaml"), WithEnvironmentOverlay()},
+			want: testNewConfig{
+				Environment: "production",
+				Host:        "base-host",
+				Port:        7000,
+				Rate:        1.5,
+				Timeout:     30 * time.Second,
+				Secret:      "shh",
+			},
+		},
 		{
 			name:     "bad json file",
 			envSetup: func(t *testing.T) {},
@@ -494,3 +601,23 @@ func TestNew(t *testing.T) {
 		}
 	})
 }
+
+func TestNewEnvironmentOverlay_AppConfig(t *testing.T) {
+	type testOverlayConfig struct {
+		AppConfig
+		Host string `envconfig:"HOST"`
+	}
+	t.Setenv("ENVIRONMENT", "production")
+	orig := os.Args
+	os.Args = []string{"test"}
+	t.Cleanup(func() { os.Args = orig })
+
+	got, err := New[testOverlayConfig](WithFile("testdata/overlay/app.json"), WithEnvironmentOverlay())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// The base file sets the environment, overriding the env var.
+	if got.Environment != "staging" || got.Host != "staging-host" {
+		t.Errorf("New() = %+v, want environment staging and host staging-host", got)
+	}
+}
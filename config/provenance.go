@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// Source identifies which config source set a field.
+type Source string
+
+const (
+	SourceInit    Source = "init"
+	SourceDefault Source = "default"
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceArg     Source = "arg"
+)
+
+// Origin records the source that last set a config field and the key it was
+// set by: the env var name, the file path, or the CLI flag.
+type Origin struct {
+	Source Source
+	Key    string
+}
+
+func (o Origin) String() string {
+	if o.Key == "" {
+		return string(o.Source)
+	}
+	return string(o.Source) + " " + o.Key
+}
+
+func (o Origin) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// Provenance records where every field of a loaded config came from.
+// Fields are keyed by their dotted file key (e.g. "port", "db.host").
+// Fields left at their zero value have no origin.
+type Provenance struct {
+	mu      sync.RWMutex
+	origins map[string]Origin
+}
+
+// WithProvenance records the source of every config field in p. When used
+// with a Watcher, p is updated after every successful reload.
+func WithProvenance(p *Provenance) Option {
+	return func(o *options) {
+		o.provenance = p
+	}
+}
+
+// Origin returns the origin of the field with the given key.
+func (p *Provenance) Origin(key string) (Origin, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	o, ok := p.origins[key]
+	return o, ok
+}
+
+// Origins returns the origin of every field that was set.
+func (p *Provenance) Origins() map[string]Origin {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return maps.Clone(p.origins)
+}
+
+func (p *Provenance) set(origins map[string]Origin) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.origins = origins
+}
+
+// recorder tracks which fields change as each source is applied to a config.
+// A source is recorded for a field when it changes the field's value, except
+// for env vars, which are recorded whenever they are set.
+type recorder struct {
+	cfg     reflect.Value
+	fields  []field
+	last    [][]byte
+	origins map[string]Origin
+}
+
+func newRecorder(cfg any, prefix string) *recorder {
+	v := reflect.ValueOf(cfg).Elem()
+	r := &recorder{
+		cfg:     v,
+		fields:  fields(v.Type(), prefix),
+		origins: map[string]Origin{},
+	}
+	r.last = r.snapshot()
+	return r
+}
+
+// record attributes every field changed since the last call to origin.
+func (r *recorder) record(origin func(f field) Origin) {
+	if r == nil {
+		return
+	}
+	current := r.snapshot()
+	for i, f := range r.fields {
+		if !bytes.Equal(r.last[i], current[i]) {
+			r.origins[f.key()] = origin(f)
+		}
+	}
+	r.last = current
+}
+
+// recordEnv attributes fields whose env var is set to the environment and
+// every other changed field to its default struct tag.
+func (r *recorder) recordEnv() {
+	if r == nil {
+		return
+	}
+	r.record(func(f field) Origin { return Origin{Source: SourceDefault} })
+	for _, f := range r.fields {
+		for _, env := range f.Lookup {
+			if _, ok := os.LookupEnv(env); ok {
+				r.origins[f.key()] = Origin{Source: SourceEnv, Key: env}
+				break
+			}
+		}
+	}
+}
+
+func (r *recorder) snapshot() [][]byte {
+	out := make([][]byte, len(r.fields))
+	for i, f := range r.fields {
+		fv, err := r.cfg.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue
+		}
+		// Values are compared by their encoding, since decoders may reuse the
+		// backing arrays of slices and maps in place.
+		b, err := json.Marshal(fv.Interface())
+		if err != nil {
+			b = fmt.Appendf(nil, "%#v", fv.Interface())
+		}
+		out[i] = b
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testProvenanceConfig struct {
+	Name  string `envconfig:"NAME"`
+	Host  string `envconfig:"HOST" default:"localhost"`
+	Port  int    `envconfig:"PORT" default:"8080"`
+	Debug bool   `envconfig:"DEBUG"`
+	Mode  string `envconfig:"MODE"`
+	Unset string `envconfig:"UNSET"`
+}
+
+func (c *testProvenanceConfig) Init() {
+	c.Name = "test-init"
+}
+
+func TestWithProvenance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("port: 9090\nmode: test-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MODE", "test-env")
+	t.Setenv("DEBUG", "false")
+	orig := os.Args
+	os.Args = []string{"test", "--host", "test-host"}
+	t.Cleanup(func() { os.Args = orig })
+
+	var p Provenance
+	if _, err := New[testProvenanceConfig](WithFile(path), WithProvenance(&p)); err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	want := map[string]Origin{
+		"name":  {Source: SourceInit},
+		"host":  {Source: SourceArg, Key: "--host"},
+		"port":  {Source: SourceFile, Key: path},
+		"debug": {Source: SourceEnv, Key: "DEBUG"},
+		"mode":  {Source: SourceFile, Key: path},
+	}
+	if got := p.Origins(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Origins() = %v, want %v", got, want)
+	}
+	if _, ok := p.Origin("unset"); ok {
+		t.Error("Origin(unset) found, want no origin for an unset field")
+	}
+}
+
+func TestOrigin_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		origin Origin
+		want   string
+	}{
+		{name: "default", origin: Origin{Source: SourceDefault}, want: "default"},
+		{name: "env", origin: Origin{Source: SourceEnv, Key: "PORT"}, want: "env PORT"},
+		{name: "file", origin: Origin{Source: SourceFile, Key: "config.yaml"}, want: "file config.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.origin.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithProvenance_Prefix(t *testing.T) {
+	t.Setenv("TEST_MODE", "test-prefixed")
+	t.Setenv("MODE", "test-unprefixed")
+	t.Setenv("NAME", "test-unprefixed")
+	orig := os.Args
+	os.Args = []string{"test"}
+	t.Cleanup(func() { os.Args = orig })
+
+	var p Provenance
+	if _, err := New[testProvenanceConfig](WithPrefix("test"), WithProvenance(&p)); err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// envconfig reads the prefixed name first, then the unprefixed tag name.
+	want := map[string]Origin{
+		"name": {Source: SourceEnv, Key: "NAME"},
+		"host": {Source: SourceDefault},
+		"port": {Source: SourceDefault},
+		"mode": {Source: SourceEnv, Key: "TEST_MODE"},
+	}
+	if got := p.Origins(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Origins() = %v, want %v", got, want)
+	}
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/go-redis/redis/extra/redisotel/v8"
@@ -9,29 +12,121 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// Redis modes select the client NewRedisClient creates.
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
 type RedisConfig struct {
-	Addr         string        `envconfig:"REDIS_ADDR" default:"localhost:6379"`
-	Password     string        `envconfig:"REDIS_PASSWORD"`
-	DB           int           `envconfig:"REDIS_DB"`
-	TLS          bool          `envconfig:"REDIS_TLS"`
-	ReadTimeout  time.Duration `envconfig:"REDIS_READ_TIMEOUT" default:"1s"`
-	WriteTimeout time.Duration `envconfig:"REDIS_WRITE_TIMEOUT" default:"5s"`
+	Mode                  string        `envconfig:"REDIS_MODE" default:"standalone" validate:"oneof=standalone sentinel cluster" help:"standalone, sentinel (failover) or cluster"`
+	Addr                  string        `envconfig:"REDIS_ADDR" default:"localhost:6379" validate:"required"`
+	Addrs                 []string      `envconfig:"REDIS_ADDRS" help:"sentinel or cluster node addresses, REDIS_ADDR when empty"`
+	MasterName            string        `envconfig:"REDIS_MASTER_NAME" validate:"required_if=Mode sentinel" help:"sentinel master name"`
+	Username              string        `envconfig:"REDIS_USERNAME" help:"ACL username"`
+	Password              string        `envconfig:"REDIS_PASSWORD" secret:"true"`
+	SentinelUsername      string        `envconfig:"REDIS_SENTINEL_USERNAME"`
+	SentinelPassword      string        `envconfig:"REDIS_SENTINEL_PASSWORD" secret:"true"`
+	DB                    int           `envconfig:"REDIS_DB" validate:"min=0"`
+	TLS                   bool          `envconfig:"REDIS_TLS"`
+	TLSCACert             string        `envconfig:"REDIS_TLS_CA_CERT" help:"CA certificate file used to verify the server, the system roots when empty"`
+	TLSCert               string        `envconfig:"REDIS_TLS_CERT" help:"client certificate file for mTLS"`
+	TLSKey                string        `envconfig:"REDIS_TLS_KEY" help:"client key file for mTLS"`
+	TLSServerName         string        `envconfig:"REDIS_TLS_SERVER_NAME"`
+	TLSInsecureSkipVerify bool          `envconfig:"REDIS_TLS_INSECURE_SKIP_VERIFY"`
+	DialTimeout           time.Duration `envconfig:"REDIS_DIAL_TIMEOUT" default:"5s"`
+	ReadTimeout           time.Duration `envconfig:"REDIS_READ_TIMEOUT" default:"1s"`
+	WriteTimeout          time.Duration `envconfig:"REDIS_WRITE_TIMEOUT" default:"5s"`
+	PoolSize              int           `envconfig:"REDIS_POOL_SIZE" validate:"min=0" help:"connections per node, 10 per CPU when 0"`
+	MinIdleConns          int           `envconfig:"REDIS_MIN_IDLE_CONNS" validate:"min=0"`
+	PoolTimeout           time.Duration `envconfig:"REDIS_POOL_TIMEOUT" validate:"min=0"`
+	IdleTimeout           time.Duration `envconfig:"REDIS_IDLE_TIMEOUT" validate:"min=0"`
+	MaxConnAge            time.Duration `envconfig:"REDIS_MAX_CONN_AGE" validate:"min=0"`
 }
 
-func NewRedisClient(cfg RedisConfig) *redis.Client {
-	opts := &redis.Options{
-		Addr:         cfg.Addr,
-		DB:           cfg.DB,
-		Password:     cfg.Password,
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
-	}
-	if cfg.TLS {
-		opts.TLSConfig = &tls.Config{
-			InsecureSkipVerify: true, // nolint:gosec
+// TLSConfig returns the TLS config for the Redis connection, or nil when TLS
+// is off. The server is verified with the CA certificate, or the system roots
+// when none is set.
+func (cfg RedisConfig) TLSConfig() (*tls.Config, error) {
+	if !cfg.TLS {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify, // nolint:gosec
+	}
+	if cfg.TLSCACert != "" {
+		b, err := os.ReadFile(cfg.TLSCACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis ca certificate: %w", err)
 		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("failed to parse redis ca certificate %s", cfg.TLSCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redis client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// UniversalOptions returns the go-redis options for every Redis mode.
+func (cfg RedisConfig) UniversalOptions() (*redis.UniversalOptions, error) {
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{cfg.Addr}
+	}
+	return &redis.UniversalOptions{
+		Addrs:            addrs,
+		MasterName:       cfg.MasterName,
+		DB:               cfg.DB,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		PoolTimeout:      cfg.PoolTimeout,
+		IdleTimeout:      cfg.IdleTimeout,
+		MaxConnAge:       cfg.MaxConnAge,
+		TLSConfig:        tlsConfig,
+	}, nil
+}
+
+// NewRedisClient creates a traced Redis client for the config's mode: a
+// single node client, a Sentinel failover client or a Cluster client.
+func NewRedisClient(cfg RedisConfig) (redis.UniversalClient, error) {
+	opts, err := cfg.UniversalOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var rc redis.UniversalClient
+	switch cfg.Mode {
+	case RedisModeSentinel:
+		rc = redis.NewFailoverClient(opts.Failover())
+	case RedisModeCluster:
+		rc = redis.NewClusterClient(opts.Cluster())
+	default:
+		simple := opts.Simple()
+		simple.Addr = cfg.Addr
+		rc = redis.NewClient(simple)
 	}
-	rc := redis.NewClient(opts)
 	rc.AddHook(redisotel.NewTracingHook(redisotel.WithAttributes(attribute.String("service.name", "redis"))))
-	return rc
+	return rc, nil
 }
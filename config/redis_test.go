@@ -0,0 +1,134 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+func TestNewRedisClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      RedisConfig
+		wantType redis.UniversalClient
+		wantErr  bool
+	}{
+		{
+			name:     "standalone",
+			cfg:      RedisConfig{Mode: RedisModeStandalone, Addr: "localhost:6379"},
+			wantType: &redis.Client{},
+		},
+		{
+			name: "sentinel",
+			cfg: RedisConfig{
+				Mode:       RedisModeSentinel,
+				Addrs:      []string{"sentinel-1:26379", "sentinel-2:26379"},
+				MasterName: "test-master",
+			},
+			wantType: &redis.Client{},
+		},
+		{
+			name:     "cluster",
+			cfg:      RedisConfig{Mode: RedisModeCluster, Addrs: []string{"node-1:6379", "node-2:6379"}},
+			wantType: &redis.ClusterClient{},
+		},
+		{
+			name:    "missing ca certificate",
+			cfg:     RedisConfig{Addr: "localhost:6379", TLS: true, TLSCACert: "testdata/missing.pem"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRedisClient(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRedisClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer got.Close()
+			if reflect.TypeOf(got) != reflect.TypeOf(tt.wantType) {
+				t.Errorf("NewRedisClient() = %T, want %T", got, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestRedisConfig_UniversalOptions(t *testing.T) {
+	cfg := RedisConfig{
+		Addr:         "localhost:6379",
+		Username:     "test-user",
+		Password:     "test-password",
+		TLS:          true,
+		PoolSize:     20,
+		MinIdleConns: 5,
+	}
+	opts, err := cfg.UniversalOptions()
+	if err != nil {
+		t.Fatalf("UniversalOptions() error = %v", err)
+	}
+	if !reflect.DeepEqual(opts.Addrs, []string{"localhost:6379"}) {
+		t.Errorf("UniversalOptions().Addrs = %v, want [localhost:6379]", opts.Addrs)
+	}
+	if opts.Username != "test-user" || opts.Password != "test-password" {
+		t.Errorf("UniversalOptions() auth = %s:%s, want test-user:test-password", opts.Username, opts.Password)
+	}
+	if opts.PoolSize != 20 || opts.MinIdleConns != 5 {
+		t.Errorf("UniversalOptions() pool = %d/%d, want 20/5", opts.PoolSize, opts.MinIdleConns)
+	}
+	if opts.TLSConfig == nil || opts.TLSConfig.InsecureSkipVerify {
+		t.Errorf("UniversalOptions().TLSConfig = %v, want verified TLS", opts.TLSConfig)
+	}
+}
+
+func TestNewRedisV9Client(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      RedisConfig
+		wantType redisv9.UniversalClient
+		wantErr  bool
+	}{
+		{
+			name:     "standalone",
+			cfg:      RedisConfig{Mode: RedisModeStandalone, Addr: "localhost:6379"},
+			wantType: &redisv9.Client{},
+		},
+		{
+			name: "sentinel",
+			cfg: RedisConfig{
+				Mode:       RedisModeSentinel,
+				Addrs:      []string{"sentinel-1:26379", "sentinel-2:26379"},
+				MasterName: "test-master",
+			},
+			wantType: &redisv9.Client{},
+		},
+		{
+			name:     "cluster",
+			cfg:      RedisConfig{Mode: RedisModeCluster, Addrs: []string{"node-1:6379", "node-2:6379"}},
+			wantType: &redisv9.ClusterClient{},
+		},
+		{
+			name:    "missing ca certificate",
+			cfg:     RedisConfig{Addr: "localhost:6379", TLS: true, TLSCACert: "testdata/missing.pem"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRedisV9Client(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRedisV9Client() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer got.Close()
+			if reflect.TypeOf(got) != reflect.TypeOf(tt.wantType) {
+				t.Errorf("NewRedisV9Client() = %T, want %T", got, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	redisv9 "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// UniversalOptionsV9 returns the go-redis v9 options for every Redis mode.
+func (cfg RedisConfig) UniversalOptionsV9() (*redisv9.UniversalOptions, error) {
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{cfg.Addr}
+	}
+	return &redisv9.UniversalOptions{
+		Addrs:            addrs,
+		MasterName:       cfg.MasterName,
+		DB:               cfg.DB,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		PoolTimeout:      cfg.PoolTimeout,
+		ConnMaxIdleTime:  cfg.IdleTimeout,
+		ConnMaxLifetime:  cfg.MaxConnAge,
+		TLSConfig:        tlsConfig,
+	}, nil
+}
+
+// NewRedisV9Client creates a go-redis v9 client for the config's mode, like
+// NewRedisClient, instrumented with redisotel tracing and metrics.
+func NewRedisV9Client(cfg RedisConfig) (redisv9.UniversalClient, error) {
+	opts, err := cfg.UniversalOptionsV9()
+	if err != nil {
+		return nil, err
+	}
+
+	var rc redisv9.UniversalClient
+	switch cfg.Mode {
+	case RedisModeSentinel:
+		rc = redisv9.NewFailoverClient(opts.Failover())
+	case RedisModeCluster:
+		rc = redisv9.NewClusterClient(opts.Cluster())
+	default:
+		simple := opts.Simple()
+		simple.Addr = cfg.Addr
+		rc = redisv9.NewClient(simple)
+	}
+
+	attrs := redisotel.WithAttributes(attribute.String("service.name", "redis"))
+	if err := redisotel.InstrumentTracing(rc, attrs); err != nil {
+		_ = rc.Close()
+		return nil, fmt.Errorf("failed to instrument redis tracing: %w", err)
+	}
+	if err := redisotel.InstrumentMetrics(rc, attrs); err != nil {
+		_ = rc.Close()
+		return nil, fmt.Errorf("failed to instrument redis metrics: %w", err)
+	}
+	return rc, nil
+}
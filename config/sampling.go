@@ -0,0 +1,246 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// samplingKeys are the span attributes, besides the span name, that sampling
+// rules match against. They are set at span start by otelhttp and otelgrpc, as
+// wired by the http middleware.Trace and grpc interceptors.ServerTrace.
+var samplingKeys = []attribute.Key{"http.route", "url.path", "http.target", "rpc.method", "rpc.service"}
+
+// SamplingRule samples the spans whose name, route or RPC method matches
+// Pattern with Sampler. Patterns are globs where * matches any characters,
+// e.g. "/health", "/admin/*" or "*Admin*".
+type SamplingRule struct {
+	Pattern string
+	Sampler trace.Sampler
+}
+
+func (r SamplingRule) matches(p trace.SamplingParameters) bool {
+	if globMatch(r.Pattern, p.Name) {
+		return true
+	}
+	var service, method string
+	for _, kv := range p.Attributes {
+		if !slices.Contains(samplingKeys, kv.Key) {
+			continue
+		}
+		v := kv.Value.Emit()
+		switch kv.Key {
+		case "rpc.service":
+			service = v
+		case "rpc.method":
+			method = v
+			// otelgrpc sets the full "service/method" as rpc.method.
+			if strings.Contains(v, "/") && globMatch(r.Pattern, "/"+v) {
+				return true
+			}
+		}
+		if globMatch(r.Pattern, v) {
+			return true
+		}
+	}
+	return service != "" && method != "" && globMatch(r.Pattern, "/"+service+"/"+method)
+}
+
+// RuleSampler samples a span with the first rule that matches it, or else
+// with fallback.
+func RuleSampler(rules []SamplingRule, fallback trace.Sampler) trace.Sampler {
+	return ruleSampler{rules: rules, fallback: fallback}
+}
+
+type ruleSampler struct {
+	rules    []SamplingRule
+	fallback trace.Sampler
+}
+
+func (s ruleSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	for _, rule := range s.rules {
+		if rule.matches(p) {
+			return rule.Sampler.ShouldSample(p)
+		}
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+func (s ruleSampler) Description() string {
+	rules := make([]string, len(s.rules))
+	for i, rule := range s.rules {
+		rules[i] = rule.Pattern + ":" + rule.Sampler.Description()
+	}
+	return fmt.Sprintf("RuleSampler{%s,fallback:%s}", strings.Join(rules, ","), s.fallback.Description())
+}
+
+// RateLimitingSampler samples the spans sampler samples, up to perSecond
+// spans a second. Spans over the limit are dropped.
+func RateLimitingSampler(perSecond float64, sampler trace.Sampler) trace.Sampler {
+	burst := max(int(perSecond), 1)
+	return rateLimitingSampler{
+		limiter: rate.NewLimiter(rate.Limit(perSecond), burst),
+		sampler: sampler,
+	}
+}
+
+type rateLimitingSampler struct {
+	limiter *rate.Limiter
+	sampler trace.Sampler
+}
+
+func (s rateLimitingSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	result := s.sampler.ShouldSample(p)
+	if result.Decision == trace.RecordAndSample && !s.limiter.Allow() {
+		result.Decision = trace.Drop
+	}
+	return result
+}
+
+func (s rateLimitingSampler) Description() string {
+	return fmt.Sprintf("RateLimitingSampler{%g,%s}", float64(s.limiter.Limit()), s.sampler.Description())
+}
+
+// recordOnlySampler records the spans sampler drops, so a TailSamplingProcessor
+// can still export them once they end.
+type recordOnlySampler struct {
+	sampler trace.Sampler
+}
+
+func (s recordOnlySampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	result := s.sampler.ShouldSample(p)
+	if result.Decision == trace.Drop {
+		result.Decision = trace.RecordOnly
+	}
+	return result
+}
+
+func (s recordOnlySampler) Description() string {
+	return s.sampler.Description()
+}
+
+// recordingParentSampler records the unsampled children of recorded parents
+// and drops the rest, so the children of never sampled spans stay dropped.
+type recordingParentSampler struct{}
+
+func (recordingParentSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	result := trace.SamplingResult{
+		Decision:   trace.Drop,
+		Tracestate: oteltrace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+	if oteltrace.SpanFromContext(p.ParentContext).IsRecording() {
+		result.Decision = trace.RecordOnly
+	}
+	return result
+}
+
+func (recordingParentSampler) Description() string {
+	return "RecordingParentSampler"
+}
+
+// Sampler returns the trace sampler for the config. Spans that match
+// OpenTelemetrySampleNever are dropped, spans that match
+// OpenTelemetrySampleAlways are sampled, and the rest are sampled at
+// OpenTelemetrySampleRate, limited to OpenTelemetrySampleRateLimit traces a
+// second when it is set. Child spans follow their parent's decision.
+//
+// When OpenTelemetrySampleErrors is set, the spans the sample rate drops are
+// recorded for a TailSamplingProcessor, but spans that match
+// OpenTelemetrySampleNever, and their children, are still dropped.
+func (cfg OpenTelemetryConfig) Sampler() trace.Sampler {
+	var sampler trace.Sampler = trace.TraceIDRatioBased(cfg.OpenTelemetrySampleRate)
+	if cfg.OpenTelemetrySampleRateLimit > 0 {
+		sampler = RateLimitingSampler(cfg.OpenTelemetrySampleRateLimit, sampler)
+	}
+	if cfg.OpenTelemetrySampleErrors {
+		sampler = recordOnlySampler{sampler: sampler}
+	}
+
+	var rules []SamplingRule
+	for _, pattern := range cfg.OpenTelemetrySampleNever {
+		rules = append(rules, SamplingRule{Pattern: pattern, Sampler: trace.NeverSample()})
+	}
+	for _, pattern := range cfg.OpenTelemetrySampleAlways {
+		rules = append(rules, SamplingRule{Pattern: pattern, Sampler: trace.AlwaysSample()})
+	}
+	if len(rules) > 0 {
+		sampler = RuleSampler(rules, sampler)
+	}
+
+	if !cfg.OpenTelemetrySampleErrors {
+		return trace.ParentBased(sampler)
+	}
+	return trace.ParentBased(sampler,
+		trace.WithRemoteParentNotSampled(recordOnlySampler{sampler: trace.NeverSample()}),
+		trace.WithLocalParentNotSampled(recordingParentSampler{}),
+	)
+}
+
+// TailSamplingProcessor passes sampled spans to next, along with the spans
+// that were recorded but not sampled for which keep returns true once they
+// end. Unsampled spans are only recorded when the sampler returns RecordOnly,
+// as the config Sampler does when OpenTelemetrySampleErrors is set.
+//
+// Spans are not buffered by trace: each kept span is exported on its own as it
+// ends, so a kept span arrives as a partial trace without the unsampled spans
+// around it.
+func TailSamplingProcessor(next trace.SpanProcessor, keep func(trace.ReadOnlySpan) bool) trace.SpanProcessor {
+	return tailSamplingProcessor{SpanProcessor: next, keep: keep}
+}
+
+// KeepErrors is a TailSamplingProcessor hook that keeps spans that ended
+// with an error status.
+func KeepErrors(s trace.ReadOnlySpan) bool {
+	return s.Status().Code == codes.Error
+}
+
+type tailSamplingProcessor struct {
+	trace.SpanProcessor
+	keep func(trace.ReadOnlySpan) bool
+}
+
+func (p tailSamplingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+	if p.keep(s) {
+		p.SpanProcessor.OnEnd(sampledSpan{ReadOnlySpan: s})
+	}
+}
+
+// sampledSpan marks a recorded span as sampled so span processors export it.
+type sampledSpan struct {
+	trace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() oteltrace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
+
+// globMatch reports whether s matches pattern, where * matches any characters.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{pattern: "/health", s: "/health", want: true},
+		{pattern: "/health", s: "/healthz"},
+		{pattern: "/admin/*", s: "/admin/users", want: true},
+		{pattern: "/admin/*", s: "/api/admin/users"},
+		{pattern: "*Admin*", s: "/pkg.AdminService/Delete", want: true},
+		{pattern: "*Admin*Delete", s: "/pkg.AdminService/Delete", want: true},
+		{pattern: "*Admin*Delete", s: "/pkg.AdminService/Get"},
+		{pattern: "a*b*b", s: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.s, func(t *testing.T) {
+			if got := globMatch(tt.pattern, tt.s); got != tt.want {
+				t.Errorf("globMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenTelemetryConfig_Sampler(t *testing.T) {
+	cfg := OpenTelemetryConfig{
+		OpenTelemetrySampleRate:   0,
+		OpenTelemetrySampleAlways: []string{"*Admin*"},
+		OpenTelemetrySampleNever:  []string{"/health", "/metrics", "/grpc.health.v1.Health/*"},
+	}
+	tests := []struct {
+		name  string
+		span  string
+		attrs []attribute.KeyValue
+		want  trace.SamplingDecision
+	}{
+		{
+			name: "ratio for the rest",
+			span: "GET /users",
+			want: trace.Drop,
+		},
+		{
+			name:  "always sampled rpc",
+			span:  "pkg.AdminService/Delete",
+			attrs: []attribute.KeyValue{attribute.String("rpc.service", "pkg.AdminService"), attribute.String("rpc.method", "Delete")},
+			want:  trace.RecordAndSample,
+		},
+		{
+			name:  "never sampled full rpc method",
+			span:  "grpc.health.v1.Health/Check",
+			attrs: []attribute.KeyValue{attribute.String("rpc.method", "grpc.health.v1.Health/Check")},
+			want:  trace.Drop,
+		},
+		{
+			name:  "never sampled route",
+			span:  "GET",
+			attrs: []attribute.KeyValue{attribute.String("url.path", "/health")},
+			want:  trace.Drop,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cfg.Sampler().ShouldSample(trace.SamplingParameters{
+				ParentContext: t.Context(),
+				TraceID:       oteltrace.TraceID{1},
+				Name:          tt.span,
+				Attributes:    tt.attrs,
+			})
+			if got.Decision != tt.want {
+				t.Errorf("ShouldSample() = %v, want %v", got.Decision, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitingSampler(t *testing.T) {
+	sampler := RateLimitingSampler(2, trace.AlwaysSample())
+	var sampled int
+	for range 10 {
+		if sampler.ShouldSample(trace.SamplingParameters{ParentContext: t.Context()}).Decision == trace.RecordAndSample {
+			sampled++
+		}
+	}
+	if sampled != 2 {
+		t.Errorf("sampled = %d, want 2", sampled)
+	}
+}
+
+func TestOpenTelemetryConfig_SamplerErrors(t *testing.T) {
+	cfg := OpenTelemetryConfig{
+		OpenTelemetrySampleRate:   0,
+		OpenTelemetrySampleNever:  []string{"/health"},
+		OpenTelemetrySampleErrors: true,
+	}
+	tests := []struct {
+		name      string
+		span      string
+		wantRoot  bool
+		wantChild bool
+	}{
+		{
+			name:      "recorded for errors",
+			span:      "/users",
+			wantRoot:  true,
+			wantChild: true,
+		},
+		{
+			name: "never sampled not recorded",
+			span: "/health",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := trace.NewTracerProvider(trace.WithSampler(cfg.Sampler())).Tracer("test")
+			ctx, root := tracer.Start(t.Context(), tt.span)
+			_, child := tracer.Start(ctx, "test-child")
+
+			if root.IsRecording() != tt.wantRoot {
+				t.Errorf("root IsRecording() = %v, want %v", root.IsRecording(), tt.wantRoot)
+			}
+			if child.IsRecording() != tt.wantChild {
+				t.Errorf("child IsRecording() = %v, want %v", child.IsRecording(), tt.wantChild)
+			}
+			if root.SpanContext().IsSampled() || child.SpanContext().IsSampled() {
+				t.Error("span sampled")
+			}
+		})
+	}
+}
+
+func TestTailSamplingProcessor(t *testing.T) {
+	cfg := OpenTelemetryConfig{OpenTelemetrySampleRate: 0, OpenTelemetrySampleErrors: true}
+	exporter := tracetest.NewInMemoryExporter()
+	tp := trace.NewTracerProvider(
+		trace.WithSampler(cfg.Sampler()),
+		trace.WithSpanProcessor(TailSamplingProcessor(trace.NewSimpleSpanProcessor(exporter), KeepErrors)),
+	)
+	tracer := tp.Tracer("test")
+
+	_, ok := tracer.Start(t.Context(), "test-ok")
+	ok.End()
+	_, failed := tracer.Start(t.Context(), "test-failed")
+	failed.SetStatus(codes.Error, "test-error")
+	failed.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "test-failed" {
+		t.Fatalf("exported spans = %v, want only test-failed", spans)
+	}
+	if !spans[0].SpanContext.IsSampled() {
+		t.Error("exported span is not marked sampled")
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// SecretResolver resolves secret references of one scheme into their values.
+// The ref is the reference with its scheme removed, e.g. "/run/secrets/db"
+// for "file:///run/secrets/db".
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// WithSecretResolver registers a SecretResolver for references that start with
+// scheme:// (e.g. "vault"), replacing any resolver already registered for it.
+// The file and env schemes are registered by default.
+func WithSecretResolver(scheme string, r SecretResolver) Option {
+	return func(o *options) {
+		o.resolvers[scheme] = r
+	}
+}
+
+// FileSecretResolver resolves file:// references to the contents of the file,
+// without a trailing newline.
+type FileSecretResolver struct{}
+
+func (FileSecretResolver) Resolve(_ context.Context, ref string) (string, error) {
+	b, err := os.ReadFile(filepath.Clean(ref))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// EnvSecretResolver resolves env:// references to the value of the env var.
+type EnvSecretResolver struct{}
+
+func (EnvSecretResolver) Resolve(_ context.Context, ref string) (string, error) {
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("env var %s is not set", ref)
+	}
+	return v, nil
+}
+
+func defaultResolvers() map[string]SecretResolver {
+	return map[string]SecretResolver{
+		"file": FileSecretResolver{},
+		"env":  EnvSecretResolver{},
+	}
+}
+
+// resolveSecrets replaces the value of every string or []byte field tagged
+// secret:"true" that holds a reference with a registered scheme.
+func resolveSecrets(ctx context.Context, cfg any, resolvers map[string]SecretResolver) error {
+	v := reflect.ValueOf(cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, f := range fields(v.Type(), "") {
+		if !f.Secret {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue
+		}
+
+		var value string
+		switch {
+		case fv.Kind() == reflect.String:
+			value = fv.String()
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+			value = string(fv.Bytes())
+		default:
+			continue
+		}
+
+		scheme, ref, ok := strings.Cut(value, "://")
+		if !ok {
+			continue
+		}
+		r, ok := resolvers[scheme]
+		if !ok {
+			continue
+		}
+		secret, err := r.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("failed to resolve secret %s: %w", f.Name, err)
+		}
+
+		if fv.Kind() == reflect.String {
+			fv.SetString(secret)
+		} else {
+			fv.SetBytes([]byte(secret))
+		}
+	}
+	return nil
+}
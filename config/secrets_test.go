@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jesse0michael/pkg/config/configtest"
+)
+
+type testSecretsConfig struct {
+	Password string `envconfig:"TEST_PASSWORD" secret:"true"`
+	Key      []byte `envconfig:"TEST_KEY" secret:"true"`
+	URL      string `envconfig:"TEST_URL"`
+}
+
+func TestNewSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	if err := os.WriteFile(path, []byte("test-file-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		envSetup func(t *testing.T)
+		opts     []Option
+		want     testSecretsConfig
+		wantErr  bool
+	}{
+		{
+			name: "plain values",
+			envSetup: func(t *testing.T) {
+				t.Setenv("TEST_PASSWORD", "test-password")
+			},
+			want: testSecretsConfig{Password: "test-password"},
+		},
+		{
+			name: "file reference",
+			envSetup: func(t *testing.T) {
+				t.Setenv("TEST_PASSWORD", "file://"+path)
+			},
+			want: testSecretsConfig{Password: "test-file-secret"},
+		},
+		{
+			name: "env reference",
+			envSetup: func(t *testing.T) {
+				t.Setenv("TEST_KEY", "env://TEST_OTHER")
+				t.Setenv("TEST_OTHER", "test-env-secret")
+			},
+			want: testSecretsConfig{Key: []byte("test-env-secret")},
+		},
+		{
+			name: "custom resolver",
+			envSetup: func(t *testing.T) {
+				t.Setenv("TEST_PASSWORD", "vault://secret/db")
+			},
+			opts: []Option{WithSecretResolver("vault", configtest.SecretResolver{"secret/db": "test-vault-secret"})},
+			want: testSecretsConfig{Password: "test-vault-secret"},
+		},
+		{
+			name: "unregistered scheme and non-secret fields untouched",
+			envSetup: func(t *testing.T) {
+				t.Setenv("TEST_PASSWORD", "vault://secret/db")
+				t.Setenv("TEST_URL", "env://TEST_OTHER")
+				t.Setenv("TEST_OTHER", "test-env-secret")
+			},
+			want: testSecretsConfig{Password: "vault://secret/db", URL: "env://TEST_OTHER"},
+		},
+		{
+			name: "unresolvable reference",
+			envSetup: func(t *testing.T) {
+				t.Setenv("TEST_PASSWORD", "env://TEST_MISSING")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.envSetup(t)
+			orig := os.Args
+			os.Args = []string{"test"}
+			t.Cleanup(func() { os.Args = orig })
+
+			got, err := New[testSecretsConfig](tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Password != tt.want.Password || string(got.Key) != string(tt.want.Key) || got.URL != tt.want.URL {
+				t.Errorf("New() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+environment = "local"
+host = "hcl-host"
+items = ["hcl-item-1"]
+
+tags {
+  source = "hcl"
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type txKey struct{}
+
+// TxFromContext returns the transaction started by WithTx, if any.
+func TxFromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// TxOrDB returns the transaction started by WithTx, or db when ctx has none,
+// so repository methods can run the same queries in and out of a transaction.
+func TxOrDB(ctx context.Context, db *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+type txOptions struct {
+	opts    *sql.TxOptions
+	retries uint
+}
+
+// TxOption configures WithTx.
+type TxOption func(*txOptions)
+
+// WithTxOptions sets the isolation level and read-only mode of the transaction.
+func WithTxOptions(opts *sql.TxOptions) TxOption {
+	return func(o *txOptions) {
+		o.opts = opts
+	}
+}
+
+// WithTxRetries sets how many times a transaction is retried after a
+// serialization failure or deadlock, 3 by default.
+func WithTxRetries(retries uint) TxOption {
+	return func(o *txOptions) {
+		o.retries = retries
+	}
+}
+
+// WithTx runs fn in a transaction stored in the context passed to fn. The
+// transaction is committed when fn returns nil and rolled back otherwise.
+// Calls to WithTx with a context that already holds a transaction join it, so
+// nested repository calls share the outermost transaction. The outermost call
+// retries fn with backoff when the transaction fails with a serialization
+// failure or deadlock.
+func WithTx(ctx context.Context, db *sqlx.DB, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	o := &txOptions{retries: 3}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	ctx, span := otel.Tracer("github.com/jesse0michael/pkg/config").Start(ctx, "db.transaction")
+	defer span.End()
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 50 * time.Millisecond
+	b.MaxInterval = time.Second
+	var attempt int
+	_, err := backoff.Retry(ctx, func() (struct{}, error) {
+		attempt++
+		span.SetAttributes(attribute.Int("db.transaction.attempt", attempt))
+		err := runTx(ctx, db, o.opts, fn)
+		if err != nil && !RetryableTxError(err) {
+			return struct{}{}, backoff.Permanent(err)
+		}
+		return struct{}{}, err
+	},
+		backoff.WithBackOff(b),
+		backoff.WithMaxTries(o.retries+1),
+		backoff.WithMaxElapsedTime(0),
+		backoff.WithNotify(func(err error, _ time.Duration) {
+			span.AddEvent("retry", trace.WithAttributes(attribute.String("error", err.Error())))
+		}),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func runTx(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions, fn func(ctx context.Context) error) (err error) {
+	tx, err := db.BeginTxx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rbErr))
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RetryableTxError returns true if the error is a Postgres serialization
+// failure or deadlock, after which the transaction can be retried.
+func RetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// 40001: "serialization_failure", 40P01: "deadlock_detected"
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestWithTx(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+	tests := []struct {
+		name      string
+		errs      []error
+		mock      func(mock sqlmock.Sqlmock)
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name: "commit",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "rollback",
+			errs: []error{errors.New("test-error")},
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "retried serialization failure",
+			errs: []error{serialization},
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "retried deadlock on commit",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit().WillReturnError(&pq.Error{Code: "40P01"})
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "retries exhausted",
+			errs: []error{serialization, serialization},
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantCalls: 2,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			tt.mock(mock)
+
+			var calls int
+			err = WithTx(t.Context(), sqlx.NewDb(db, "sqlmock"), func(ctx context.Context) error {
+				calls++
+				if _, ok := TxFromContext(ctx); !ok {
+					t.Error("TxFromContext() ok = false, want true")
+				}
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			}, WithTxRetries(1))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WithTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("WithTx() calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestWithTx_Nested(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO test").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	sdb := sqlx.NewDb(db, "sqlmock")
+	err = WithTx(t.Context(), sdb, func(ctx context.Context) error {
+		outer, _ := TxFromContext(ctx)
+		return WithTx(ctx, sdb, func(ctx context.Context) error {
+			if inner, _ := TxFromContext(ctx); inner != outer {
+				t.Error("nested WithTx() started a new transaction")
+			}
+			_, err := TxOrDB(ctx, sdb).ExecContext(ctx, "INSERT INTO test VALUES (1)")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
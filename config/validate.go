@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = validator.New()
+
+// FieldError describes a config field that failed a validate struct tag rule,
+// along with the env var, file key and CLI flag that can set it.
+type FieldError struct {
+	Field string
+	Rule  string
+	Env   string
+	File  string
+	Flag  string
+}
+
+func (e *FieldError) Error() string {
+	var sources []string
+	if e.Env != "" {
+		sources = append(sources, "env "+e.Env)
+	}
+	if e.File != "" {
+		sources = append(sources, "file key "+e.File)
+	}
+	if e.Flag != "" {
+		sources = append(sources, "flag "+e.Flag)
+	}
+	msg := fmt.Sprintf("%s failed %q validation", e.Field, e.Rule)
+	if len(sources) > 0 {
+		msg += " (" + strings.Join(sources, ", ") + ")"
+	}
+	return msg
+}
+
+// validateConfig checks the validate struct tags of cfg and, if cfg implements
+// Validator, its Validate method. All failures are joined into one error.
+func validateConfig(cfg any, prefix string) error {
+	var errs []error
+	if reflect.Indirect(reflect.ValueOf(cfg)).Kind() == reflect.Struct {
+		err := validate.Struct(cfg)
+		var verrs validator.ValidationErrors
+		if errors.As(err, &verrs) {
+			byName := map[string]field{}
+			for _, f := range fields(reflect.TypeOf(cfg), prefix) {
+				byName[f.Name] = f
+			}
+			for _, fe := range verrs {
+				// Drop the root struct name so the namespace matches the field path.
+				_, name, _ := strings.Cut(fe.StructNamespace(), ".")
+				rule := fe.Tag()
+				if fe.Param() != "" {
+					rule += "=" + fe.Param()
+				}
+				f := byName[name]
+				errs = append(errs, &FieldError{Field: name, Rule: rule, Env: f.Env, File: f.File, Flag: f.Flag})
+			}
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if v, ok := cfg.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
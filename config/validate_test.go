@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type testValidateSub struct {
+	DSN string `envconfig:"TEST_DSN" validate:"required"`
+}
+
+type testValidateConfig struct {
+	Port int             `envconfig:"PORT" default:"8080" validate:"min=1,max=65535"`
+	Sub  testValidateSub `json:"sub" arg:"-"`
+	Mode string          `envconfig:"MODE" default:"a"`
+}
+
+func (c *testValidateConfig) Validate() error {
+	if c.Mode == "invalid" {
+		return errors.New("test-error")
+	}
+	return nil
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		envSetup func(t *testing.T)
+		wantErrs []string
+	}{
+		{
+			name: "valid",
+			envSetup: func(t *testing.T) {
+				t.Setenv("TEST_DSN", "test-dsn")
+			},
+		},
+		{
+			name: "struct tag failures name every source",
+			envSetup: func(t *testing.T) {
+				t.Setenv("PORT", "70000")
+			},
+			wantErrs: []string{
+				`Port failed "max=65535" validation (env PORT, file key port, flag --port)`,
+				`Sub.DSN failed "required" validation (env TEST_DSN, file key sub.dsn)`,
+			},
+		},
+		{
+			name: "validate method failure",
+			envSetup: func(t *testing.T) {
+				t.Setenv("TEST_DSN", "test-dsn")
+				t.Setenv("MODE", "invalid")
+			},
+			wantErrs: []string{"test-error"},
+		},
+		{
+			name: "struct tag and validate method failures joined",
+			envSetup: func(t *testing.T) {
+				t.Setenv("MODE", "invalid")
+			},
+			wantErrs: []string{
+				`Sub.DSN failed "required" validation`,
+				"test-error",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.envSetup(t)
+			orig := os.Args
+			os.Args = []string{"test"}
+			t.Cleanup(func() { os.Args = orig })
+
+			_, err := New[testValidateConfig]()
+			if (err != nil) != (len(tt.wantErrs) > 0) {
+				t.Fatalf("New() error = %v, wantErrs %v", err, tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("New() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewValidation_FieldError(t *testing.T) {
+	orig := os.Args
+	os.Args = []string{"test"}
+	t.Cleanup(func() { os.Args = orig })
+
+	_, err := New[testValidateConfig]()
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("New() error = %v, want a *FieldError", err)
+	}
+	want := FieldError{Field: "Sub.DSN", Rule: "required", Env: "TEST_DSN", File: "sub.dsn"}
+	if *fe != want {
+		t.Errorf("FieldError = %+v, want %+v", *fe, want)
+	}
+}
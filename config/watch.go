@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay debounces bursts of file events, since editors and config
+// management tools often write a file in several steps.
+const reloadDelay = 100 * time.Millisecond
+
+// Watcher holds a config of type T that is reloaded whenever one of the
+// config files changes. It is safe for concurrent reads.
+type Watcher[T any] struct {
+	opts        options
+	value       atomic.Pointer[T]
+	reloadMu    sync.Mutex
+	mu          sync.RWMutex
+	subscribers []func(T)
+	fsw         *fsnotify.Watcher
+	cancel      context.CancelFunc
+}
+
+// NewWatcher loads a config of type T the same way as New and watches the
+// WithFile files, their environment overlays and the WithDir directories
+// for changes. On change the config is reloaded from every
+// source and, if it loads and validates, swapped in and passed to subscribers.
+// A config that fails to reload is logged and the previous value is kept.
+// The watch runs in a goroutine until ctx is cancelled or Stop is called.
+func NewWatcher[T any](ctx context.Context, opts ...Option) (*Watcher[T], error) {
+	w := &Watcher[T]{opts: newOptions(opts)}
+
+	cfg, err := load[T](ctx, w.opts)
+	if err != nil {
+		return nil, err
+	}
+	w.value.Store(&cfg)
+
+	w.fsw, err = fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	// Directories are watched instead of the files so that files replaced
+	// by a rename (atomic writes) or created later are still seen.
+	for _, dir := range w.dirs() {
+		if err := w.fsw.Add(dir); err != nil && !os.IsNotExist(err) {
+			_ = w.fsw.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	go w.watch(ctx)
+	return w, nil
+}
+
+// Load returns the current config.
+func (w *Watcher[T]) Load() T {
+	return *w.value.Load()
+}
+
+// Subscribe registers f to be called with the new config after every reload
+// that changes it.
+func (w *Watcher[T]) Subscribe(f func(T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, f)
+}
+
+// Stop stops watching the config files.
+func (w *Watcher[T]) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+}
+
+// Reload loads the config from every source and swaps it in if it is valid.
+// Subscribers are notified only when the new config differs from the current one.
+func (w *Watcher[T]) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	cfg, err := load[T](context.Background(), w.opts)
+	if err != nil {
+		return err
+	}
+
+	old := w.value.Swap(&cfg)
+	if reflect.DeepEqual(*old, cfg) {
+		return nil
+	}
+
+	w.mu.RLock()
+	subscribers := slices.Clone(w.subscribers)
+	w.mu.RUnlock()
+	for _, f := range subscribers {
+		f(cfg)
+	}
+	return nil
+}
+
+func (w *Watcher[T]) watch(ctx context.Context) {
+	defer w.fsw.Close()
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if !w.watching(event.Name) {
+				continue
+			}
+			if timer == nil {
+				timer = time.AfterFunc(reloadDelay, func() {
+					if err := w.Reload(); err != nil {
+						slog.ErrorContext(ctx, "failed to reload config", "err", err)
+						return
+					}
+					slog.InfoContext(ctx, "config reloaded")
+				})
+			} else {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			slog.ErrorContext(ctx, "config file watcher error", "err", err)
+		}
+	}
+}
+
+func (w *Watcher[T]) dirs() []string {
+	var dirs []string
+	for _, f := range w.opts.files {
+		dir := filepath.Clean(f.path)
+		if !f.dir {
+			dir = filepath.Dir(dir)
+		}
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func (w *Watcher[T]) watching(name string) bool {
+	for _, f := range w.opts.files {
+		if f.watches(name, w.opts.overlay) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testWatchConfig struct {
+	Host string `envconfig:"HOST" default:"localhost"`
+	Port int    `envconfig:"PORT" default:"8080"`
+}
+
+func (c *testWatchConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+	return nil
+}
+
+func TestWatcher(t *testing.T) {
+	orig := os.Args
+	os.Args = []string{"test"}
+	t.Cleanup(func() { os.Args = orig })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("host: test-host-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher[testWatchConfig](t.Context(), WithFile(path))
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	t.Cleanup(w.Stop)
+
+	if got := w.Load(); got.Host != "test-host-1" || got.Port != 8080 {
+		t.Fatalf("Load() = %+v, want host test-host-1 port 8080", got)
+	}
+
+	changed := make(chan testWatchConfig, 1)
+	w.Subscribe(func(cfg testWatchConfig) { changed <- cfg })
+
+	if err := os.WriteFile(path, []byte("host: test-host-2\nport: 9090\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case cfg := <-changed:
+		if cfg.Host != "test-host-2" || cfg.Port != 9090 {
+			t.Errorf("subscriber got %+v, want host test-host-2 port 9090", cfg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber was not notified of the file change")
+	}
+	if got := w.Load(); got.Host != "test-host-2" {
+		t.Errorf("Load() = %+v, want host test-host-2", got)
+	}
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	orig := os.Args
+	os.Args = []string{"test"}
+	t.Cleanup(func() { os.Args = orig })
+
+	tests := []struct {
+		name     string
+		contents string
+		want     testWatchConfig
+		notified bool
+		wantErr  bool
+	}{
+		{
+			name:     "changed config swapped in",
+			contents: "host: test-host-2\n",
+			want:     testWatchConfig{Host: "test-host-2", Port: 8080},
+			notified: true,
+		},
+		{
+			name:     "unchanged config not notified",
+			contents: "host: test-host-1\n",
+			want:     testWatchConfig{Host: "test-host-1", Port: 8080},
+		},
+		{
+			name:     "unparsable config keeps previous",
+			contents: "not valid { yaml",
+			want:     testWatchConfig{Host: "test-host-1", Port: 8080},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid config keeps previous",
+			contents: "host: test-host-2\nport: -1\n",
+			want:     testWatchConfig{Host: "test-host-1", Port: 8080},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte("host: test-host-1\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			w, err := NewWatcher[testWatchConfig](t.Context(), WithFile(path))
+			if err != nil {
+				t.Fatalf("NewWatcher() error = %v", err)
+			}
+			w.Stop()
+
+			var notified bool
+			w.Subscribe(func(testWatchConfig) { notified = true })
+
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Reload(); (err != nil) != tt.wantErr {
+				t.Errorf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := w.Load(); got != tt.want {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+			if notified != tt.notified {
+				t.Errorf("notified = %v, want %v", notified, tt.notified)
+			}
+		})
+	}
+}
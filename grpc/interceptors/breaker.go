@@ -0,0 +1,194 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jesse0michael/pkg/resilience"
+	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrCircuitOpen  = status.Error(codes.Unavailable, "circuit breaker open")
+	ErrBulkheadFull = status.Error(codes.ResourceExhausted, "too many concurrent requests")
+)
+
+// BreakerConfig configures the circuit breaker of each method.
+type BreakerConfig struct {
+	// Timeout is how long a breaker stays open before probing the method.
+	Timeout time.Duration `envconfig:"GRPC_CLIENT_BREAKER_TIMEOUT" default:"60s"`
+	// Failures trips a breaker after more than this many consecutive failures.
+	Failures uint32 `envconfig:"GRPC_CLIENT_BREAKER_FAILURES" default:"5"`
+	// Interval clears the counts of a closed breaker this often. 0 never clears them.
+	Interval time.Duration `envconfig:"GRPC_CLIENT_BREAKER_INTERVAL"`
+	// MaxRequests is the number of calls let through to probe a half-open breaker.
+	MaxRequests uint32 `envconfig:"GRPC_CLIENT_BREAKER_MAX_REQUESTS" default:"1"`
+}
+
+// ResilienceOption configures a CircuitBreaker or Bulkhead.
+type ResilienceOption = resilience.Option
+
+// WithMeterProvider sets the meter provider that records breaker and bulkhead
+// metrics, the global meter provider by default.
+func WithMeterProvider(mp metric.MeterProvider) ResilienceOption {
+	return resilience.WithMeterProvider(mp)
+}
+
+// breakerFailure reports whether err means the server is unhealthy, as opposed
+// to a call the server rejected.
+func breakerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// CircuitBreaker keeps a circuit breaker per method for gRPC client calls, so
+// a failing method fails fast with ErrCircuitOpen. Unavailable,
+// DeadlineExceeded, Internal and Unknown errors count as failures, except
+// those of calls whose own context was canceled or timed out. State
+// changes are logged and each method's state (0 closed, 1 half-open, 2 open)
+// is recorded as the rpc.client.breaker.state metric.
+type CircuitBreaker struct {
+	breaker *resilience.Breaker
+}
+
+// NewCircuitBreaker creates a per method circuit breaker.
+func NewCircuitBreaker(cfg BreakerConfig, opts ...ResilienceOption) *CircuitBreaker {
+	return &CircuitBreaker{
+		breaker: resilience.NewBreaker("rpc.client", "rpc.method", resilience.Config{
+			Timeout:     cfg.Timeout,
+			Failures:    cfg.Failures,
+			Interval:    cfg.Interval,
+			MaxRequests: cfg.MaxRequests,
+		}, opts...),
+	}
+}
+
+// State returns the breaker state of method.
+func (cb *CircuitBreaker) State(method string) gobreaker.State {
+	return cb.breaker.State(method)
+}
+
+// UnaryClientInterceptor returns a gRPC unary client interceptor that guards
+// each method with its circuit breaker.
+func (cb *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return cb.execute(ctx, method, func() error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// StreamClientInterceptor returns a gRPC stream client interceptor that guards
+// opening streams of each method with its circuit breaker. Only the outcome of
+// opening a stream counts toward the breaker: errors from SendMsg and RecvMsg
+// on an open stream are neither counted nor rejected, so a stream that fails
+// after it's established doesn't trip the breaker.
+func (cb *CircuitBreaker) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var stream grpc.ClientStream
+		err := cb.execute(ctx, method, func() error {
+			var err error
+			stream, err = streamer(ctx, desc, cc, method, opts...)
+			return err
+		})
+		return stream, err
+	}
+}
+
+func (cb *CircuitBreaker) execute(ctx context.Context, method string, call func() error) error {
+	var callErr error
+	_, err := cb.breaker.Execute(ctx, method, func() (any, error) {
+		callErr = call()
+		// Calls that fail because the caller canceled or timed out its own
+		// context say nothing about the server's health.
+		if ctx.Err() == nil && breakerFailure(callErr) {
+			return nil, callErr
+		}
+		return nil, nil
+	})
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return ErrCircuitOpen
+	}
+	return callErr
+}
+
+// Bulkhead caps the number of gRPC client calls in flight per method, so a
+// slow method can't tie up every connection and goroutine. Calls over the cap
+// fail immediately with ErrBulkheadFull and are counted by the
+// rpc.client.bulkhead.rejected metric.
+type Bulkhead struct {
+	bulkhead *resilience.Bulkhead
+}
+
+// NewBulkhead creates a bulkhead allowing up to maxConcurrent calls in flight
+// per method, or any number when it's 0.
+func NewBulkhead(maxConcurrent int, opts ...ResilienceOption) *Bulkhead {
+	return &Bulkhead{
+		bulkhead: resilience.NewBulkhead("rpc.client", "rpc.method", maxConcurrent, opts...),
+	}
+}
+
+// UnaryClientInterceptor returns a gRPC unary client interceptor that caps the
+// calls in flight per method.
+func (b *Bulkhead) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		release, err := b.acquire(ctx, method)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a gRPC stream client interceptor that caps
+// the open streams per method. A stream holds its slot until it ends or its
+// context is done.
+func (b *Bulkhead) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		release, err := b.acquire(ctx, method)
+		if err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		stop := context.AfterFunc(stream.Context(), release)
+		return &releaseStream{ClientStream: stream, release: func() {
+			stop()
+			release()
+		}}, nil
+	}
+}
+
+func (b *Bulkhead) acquire(ctx context.Context, method string) (func(), error) {
+	release, err := b.bulkhead.Acquire(ctx, method)
+	if errors.Is(err, resilience.ErrBulkheadFull) {
+		return nil, ErrBulkheadFull
+	}
+	return release, err
+}
+
+// releaseStream frees a bulkhead slot once the stream ends.
+type releaseStream struct {
+	grpc.ClientStream
+	release func()
+}
+
+func (s *releaseStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.release()
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreakerUnaryClientInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		calls     int
+		wantState gobreaker.State
+		wantErr   error
+	}{
+		{
+			name:      "success",
+			calls:     3,
+			wantState: gobreaker.StateClosed,
+		},
+		{
+			name:      "client errors don't trip",
+			err:       status.Error(codes.NotFound, "test-error"),
+			calls:     3,
+			wantState: gobreaker.StateClosed,
+			wantErr:   status.Error(codes.NotFound, "test-error"),
+		},
+		{
+			name:      "unavailable below failures",
+			err:       status.Error(codes.Unavailable, "test-error"),
+			calls:     2,
+			wantState: gobreaker.StateClosed,
+			wantErr:   status.Error(codes.Unavailable, "test-error"),
+		},
+		{
+			name:      "unavailable trips",
+			err:       status.Error(codes.Unavailable, "test-error"),
+			calls:     4,
+			wantState: gobreaker.StateOpen,
+			wantErr:   ErrCircuitOpen,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := NewCircuitBreaker(BreakerConfig{Failures: 2, Timeout: time.Hour})
+			interceptor := cb.UnaryClientInterceptor()
+			invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				return tt.err
+			}
+
+			var err error
+			for range tt.calls {
+				err = interceptor(t.Context(), "/test.Service/Method", nil, nil, nil, invoker)
+			}
+
+			if status.Code(err) != status.Code(tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("got err=%v, want %v", err, tt.wantErr)
+			}
+			if got := cb.State("/test.Service/Method"); got != tt.wantState {
+				t.Errorf("State() = %v, want %v", got, tt.wantState)
+			}
+			if got := cb.State("/test.Service/Other"); got != gobreaker.StateClosed {
+				t.Errorf("State() other method = %v, want closed", got)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerUnaryClientInterceptor_CallerContext(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{Failures: 1, Timeout: time.Hour})
+	interceptor := cb.UnaryClientInterceptor()
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	canceled, cancel := context.WithCancel(t.Context())
+	cancel()
+	expired, cancelExpired := context.WithTimeout(t.Context(), time.Millisecond)
+	defer cancelExpired()
+	for _, ctx := range []context.Context{canceled, expired, canceled} {
+		if err := interceptor(ctx, "/test.Service/Method", nil, nil, nil, invoker); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("got err=%v, want the caller's context error", err)
+		}
+	}
+	if got := cb.State("/test.Service/Method"); got != gobreaker.StateClosed {
+		t.Errorf("State() = %v, want closed", got)
+	}
+}
+
+func TestCircuitBreakerStreamClientInterceptor(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{Failures: 1, Timeout: time.Hour})
+	interceptor := cb.StreamClientInterceptor()
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return nil, status.Error(codes.Unavailable, "test-error")
+	}
+
+	for range 2 {
+		if _, err := interceptor(t.Context(), nil, nil, "/test.Service/Stream", streamer); status.Code(err) != codes.Unavailable {
+			t.Fatalf("got err=%v, want Unavailable", err)
+		}
+	}
+	if _, err := interceptor(t.Context(), nil, nil, "/test.Service/Stream", streamer); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got err=%v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestBulkheadUnaryClientInterceptor(t *testing.T) {
+	interceptor := NewBulkhead(1).UnaryClientInterceptor()
+	var inner error
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		// A nested call to the same method while the first is in flight.
+		inner = interceptor(ctx, method, req, reply, cc, func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			return nil
+		})
+		return nil
+	}
+
+	if err := interceptor(t.Context(), "/test.Service/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("got err=%v", err)
+	}
+	if !errors.Is(inner, ErrBulkheadFull) {
+		t.Errorf("got concurrent err=%v, want ErrBulkheadFull", inner)
+	}
+	if err := interceptor(t.Context(), "/test.Service/Method", nil, nil, nil, invoker); err != nil {
+		t.Errorf("got err=%v after release", err)
+	}
+}
+
+type testClientStream struct {
+	grpc.ClientStream
+	ctx context.Context
+}
+
+func (s testClientStream) Context() context.Context { return s.ctx }
+
+func TestBulkheadStreamClientInterceptor(t *testing.T) {
+	interceptor := NewBulkhead(1).StreamClientInterceptor()
+	ctx, cancel := context.WithCancel(t.Context())
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return testClientStream{ctx: ctx}, nil
+	}
+
+	if _, err := interceptor(t.Context(), nil, nil, "/test.Service/Stream", streamer); err != nil {
+		t.Fatalf("got err=%v", err)
+	}
+	if _, err := interceptor(t.Context(), nil, nil, "/test.Service/Stream", streamer); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("got err=%v with open stream, want ErrBulkheadFull", err)
+	}
+
+	// Ending the stream frees its slot.
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, err := interceptor(t.Context(), nil, nil, "/test.Service/Stream", streamer)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got err=%v after stream ended", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
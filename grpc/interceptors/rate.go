@@ -15,6 +15,8 @@ import (
 var ErrRateLimited = status.Error(codes.ResourceExhausted, "rate limit exceeded")
 
 // RateLimitConfig configures the rate limiting interceptor.
+//
+// Deprecated: Use github.com/jesse0michael/pkg/ratelimit.Config.
 type RateLimitConfig struct {
 	// Rate is the token refill rate (per second) for authenticated users.
 	// Default ~1.67/s = 100 requests per minute.
@@ -34,12 +36,18 @@ type RateLimitConfig struct {
 //
 // TODO: The rates sync.Map will grow throughout the lifetime of the server.
 // Eviction or a periodic fresh swap should be considered.
+//
+// Deprecated: Use github.com/jesse0michael/pkg/ratelimit, which evicts idle
+// keys and shares its limits with HTTP middleware and across replicas through
+// Redis.
 type RateLimiter struct {
 	cfg   RateLimitConfig
 	rates sync.Map // map[string]*rate.Limiter
 }
 
 // NewRateLimiter creates a RateLimiter with the given configuration.
+//
+// Deprecated: Use github.com/jesse0michael/pkg/ratelimit.New.
 func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
 	return &RateLimiter{
 		cfg: cfg,
@@ -0,0 +1,17 @@
+package interceptors
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// ServerTrace returns a gRPC server option that starts an OpenTelemetry server
+// span for each RPC with otelgrpc. The span is named "service/method" and
+// starts with the full method as rpc.method, so trace samplers such as the
+// config RuleSampler can sample RPCs by service or method.
+//
+// Tracing is a stats handler rather than an interceptor, so the span covers
+// the whole RPC, including the interceptors that run before the handler.
+func ServerTrace(opts ...otelgrpc.Option) grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(opts...))
+}
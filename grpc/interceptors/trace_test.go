@@ -0,0 +1,70 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startSampler records the name and attributes of the spans it samples.
+type startSampler struct {
+	mu    sync.Mutex
+	name  string
+	attrs map[attribute.Key]string
+}
+
+func (s *startSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = p.Name
+	s.attrs = map[attribute.Key]string{}
+	for _, kv := range p.Attributes {
+		s.attrs[kv.Key] = kv.Value.Emit()
+	}
+	return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample}
+}
+
+func (s *startSampler) Description() string { return "startSampler" }
+
+func TestServerTrace(t *testing.T) {
+	sampler := &startSampler{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler))
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(ServerTrace(otelgrpc.WithTracerProvider(tp)))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	if _, err := healthpb.NewHealthClient(conn).Check(t.Context(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("failed to check health: %v", err)
+	}
+
+	sampler.mu.Lock()
+	defer sampler.mu.Unlock()
+	if want := "grpc.health.v1.Health/Check"; sampler.name != want {
+		t.Errorf("span name at start: got %q, want %q", sampler.name, want)
+	}
+	if got, want := sampler.attrs["rpc.method"], "grpc.health.v1.Health/Check"; got != want {
+		t.Errorf("rpc.method at start: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jesse0michael/pkg/resilience"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrBulkheadFull is returned when a host already has the maximum number of
+// requests in flight.
+var ErrBulkheadFull = resilience.ErrBulkheadFull
+
+// errServerError marks a 5xx response as a breaker failure without failing the
+// request.
+var errServerError = errors.New("server error")
+
+// BreakerConfig configures the circuit breaker of each host.
+type BreakerConfig struct {
+	// Timeout is how long a breaker stays open before probing the host.
+	Timeout time.Duration `envconfig:"HTTP_CLIENT_BREAKER_TIMEOUT" default:"60s"`
+	// Failures trips a breaker after more than this many consecutive failures.
+	Failures uint32 `envconfig:"HTTP_CLIENT_BREAKER_FAILURES" default:"5"`
+	// Interval clears the counts of a closed breaker this often. 0 never clears them.
+	Interval time.Duration `envconfig:"HTTP_CLIENT_BREAKER_INTERVAL"`
+	// MaxRequests is the number of requests let through to probe a half-open breaker.
+	MaxRequests uint32 `envconfig:"HTTP_CLIENT_BREAKER_MAX_REQUESTS" default:"1"`
+}
+
+// ResilienceOption configures a Breaker or Bulkhead.
+type ResilienceOption = resilience.Option
+
+// WithMeterProvider sets the meter provider that records breaker and bulkhead
+// metrics, the global meter provider by default.
+func WithMeterProvider(mp metric.MeterProvider) ResilienceOption {
+	return resilience.WithMeterProvider(mp)
+}
+
+// Breaker keeps a circuit breaker per host, so a failing host fails fast
+// without affecting calls to other hosts. Transport errors and 5xx responses
+// count as failures, unless the request's own context was canceled or timed
+// out. State changes are logged and each host's state (0 closed, 1 half-open,
+// 2 open) is recorded as the http.client.breaker.state metric.
+type Breaker = resilience.Breaker
+
+// NewBreaker creates a per host circuit breaker.
+func NewBreaker(cfg BreakerConfig, opts ...ResilienceOption) *Breaker {
+	return resilience.NewBreaker("http.client", "server.address", resilience.Config{
+		Timeout:     cfg.Timeout,
+		Failures:    cfg.Failures,
+		Interval:    cfg.Interval,
+		MaxRequests: cfg.MaxRequests,
+	}, opts...)
+}
+
+// Bulkhead caps the number of requests in flight to each host, so a slow host
+// can't tie up every connection and goroutine. Requests over the cap fail
+// immediately with ErrBulkheadFull and are counted by the
+// http.client.bulkhead.rejected metric.
+type Bulkhead = resilience.Bulkhead
+
+// NewBulkhead creates a bulkhead allowing up to maxConcurrent requests in
+// flight per host, or any number when it's 0.
+func NewBulkhead(maxConcurrent int, opts ...ResilienceOption) *Bulkhead {
+	return resilience.NewBulkhead("http.client", "server.address", maxConcurrent, opts...)
+}
+
+// ResilientTransport is an http.RoundTripper that guards each host with a
+// Breaker and a Bulkhead.
+type ResilientTransport struct {
+	next     http.RoundTripper
+	breaker  *Breaker
+	bulkhead *Bulkhead
+}
+
+// NewResilientTransport wraps next, http.DefaultTransport when nil, with the
+// breaker and bulkhead. Either may be nil to skip it.
+func NewResilientTransport(next http.RoundTripper, breaker *Breaker, bulkhead *Bulkhead) *ResilientTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &ResilientTransport{
+		next:     next,
+		breaker:  breaker,
+		bulkhead: bulkhead,
+	}
+}
+
+// RoundTrip holds the bulkhead slot of a request until its response body is
+// closed.
+func (t *ResilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	release := func() {}
+	if t.bulkhead != nil {
+		var err error
+		if release, err = t.bulkhead.Acquire(ctx, host); err != nil {
+			return nil, fmt.Errorf("failed to call %s: %w", host, err)
+		}
+	}
+
+	resp, err := t.roundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (t *ResilientTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if t.breaker == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	host := req.URL.Host
+	// A request canceled or timed out by its caller says nothing about the
+	// host, so its error is kept out of the breaker.
+	var callerErr error
+	result, err := t.breaker.Execute(req.Context(), host, func() (any, error) {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil && req.Context().Err() != nil {
+			callerErr = err
+			return nil, nil
+		}
+		if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+			return resp, errServerError
+		}
+		return resp, err
+	})
+	if errors.Is(err, errServerError) {
+		return result.(*http.Response), nil
+	}
+	if err == nil {
+		err = callerErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", host, err)
+	}
+	return result.(*http.Response), nil
+}
+
+// releaseBody frees a bulkhead slot when the response body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestResilientTransport_Breaker(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		requests   int
+		wantState  gobreaker.State
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			status:     http.StatusOK,
+			requests:   3,
+			wantState:  gobreaker.StateClosed,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "client errors don't trip",
+			status:     http.StatusNotFound,
+			requests:   3,
+			wantState:  gobreaker.StateClosed,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "server errors below failures",
+			status:     http.StatusInternalServerError,
+			requests:   2,
+			wantState:  gobreaker.StateClosed,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:      "server errors trip",
+			status:    http.StatusInternalServerError,
+			requests:  4,
+			wantState: gobreaker.StateOpen,
+			wantErr:   gobreaker.ErrOpenState,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(srv.Close)
+
+			breaker := NewBreaker(BreakerConfig{Failures: 2, Timeout: time.Hour})
+			c := &http.Client{Transport: NewResilientTransport(nil, breaker, nil)}
+			var resp *http.Response
+			var err error
+			for range tt.requests {
+				resp, err = c.Get(srv.URL)
+				if err == nil {
+					_ = resp.Body.Close()
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && resp.StatusCode != tt.wantStatus {
+				t.Errorf("Get() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			u, _ := url.Parse(srv.URL)
+			if got := breaker.State(u.Host); got != tt.wantState {
+				t.Errorf("Breaker.State() = %v, want %v", got, tt.wantState)
+			}
+			if got := breaker.State("test-host"); got != gobreaker.StateClosed {
+				t.Errorf("Breaker.State() other host = %v, want closed", got)
+			}
+		})
+	}
+}
+
+func TestResilientTransport_BreakerCanceled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	breaker := NewBreaker(BreakerConfig{Failures: 1, Timeout: time.Hour})
+	c := &http.Client{Transport: NewResilientTransport(nil, breaker, nil)}
+	for range 3 {
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if _, err := c.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Do() error = %v, want context.DeadlineExceeded", err)
+		}
+		cancel()
+	}
+
+	u, _ := url.Parse(srv.URL)
+	if got := breaker.State(u.Host); got != gobreaker.StateClosed {
+		t.Errorf("Breaker.State() = %v, want closed", got)
+	}
+}
+
+func TestResilientTransport_Bulkhead(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	reader := sdkmetric.NewManualReader()
+	bulkhead := NewBulkhead(1, WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	c := &http.Client{Transport: NewResilientTransport(nil, nil, bulkhead)}
+
+	// The slot is held until the body of the first response is closed.
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := c.Get(srv.URL); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Get() error = %v, want ErrBulkheadFull", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	if got := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints[0].Value; got != 1 {
+		t.Errorf("http.client.bulkhead.rejected = %d, want 1", got)
+	}
+
+	_ = resp.Body.Close()
+	free, err := bulkhead.Acquire(t.Context(), resp.Request.URL.Host)
+	if err != nil {
+		t.Fatalf("Bulkhead.Acquire() after close error = %v", err)
+	}
+	free()
+}
+
+func TestNewWithResilience(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(
+		WithHTTPClient(&http.Client{}),
+		WithBreaker(NewBreaker(BreakerConfig{Failures: 1, Timeout: time.Hour})),
+		WithBulkhead(NewBulkhead(10)),
+	)
+	for range 2 {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL, nil)
+		_ = c.Process(t.Context(), req, nil)
+	}
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL, nil)
+	if err := c.Process(t.Context(), req, nil); !errors.Is(err, gobreaker.ErrOpenState) {
+		t.Errorf("Process() error = %v, want ErrOpenState", err)
+	}
+}
@@ -30,6 +30,8 @@ type REST struct {
 	headers  http.Header
 	logger   *slog.Logger
 	maxBytes int64
+	breaker  *Breaker
+	bulkhead *Bulkhead
 }
 
 // Option configures a REST client.
@@ -48,6 +50,11 @@ func New(opts ...Option) *REST {
 	for _, o := range opts {
 		o(r)
 	}
+	if r.breaker != nil || r.bulkhead != nil {
+		c := *r.client
+		c.Transport = NewResilientTransport(c.Transport, r.breaker, r.bulkhead)
+		r.client = &c
+	}
 	return r
 }
 
@@ -94,6 +101,17 @@ func WithMaxResponseBytes(n int64) Option {
 	return func(r *REST) { r.maxBytes = n }
 }
 
+// WithBreaker guards each host the client calls with a circuit breaker.
+func WithBreaker(b *Breaker) Option {
+	return func(r *REST) { r.breaker = b }
+}
+
+// WithBulkhead caps the number of requests in flight to each host the client
+// calls.
+func WithBulkhead(b *Bulkhead) Option {
+	return func(r *REST) { r.bulkhead = b }
+}
+
 // Process sends req and writes the response into out, choosing how based on the
 // type of out:
 //
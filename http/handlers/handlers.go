@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/pprof"
 	"slices"
@@ -39,23 +40,38 @@ func HandlePprof() http.Handler {
 	return mux
 }
 
-// HealthChecker reports whether a dependency is healthy.
+// HealthChecker reports whether a dependency is healthy. An error with a
+// Degraded() bool method that returns true reports a dependency the service
+// can run without, like a cache behind an open circuit breaker.
 type HealthChecker interface {
 	Healthy(ctx context.Context) error
 }
 
 // HandleHealth returns a health check handler. When called with no checkers it
 // always returns 200. When checkers are provided, each is called and any
-// failure results in a 503.
+// failure results in a 503, while degraded checkers still return 200 with a
+// degraded message.
 func HandleHealth(checkers ...HealthChecker) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
+		var degraded bool
 		for _, c := range checkers {
-			if err := c.Healthy(r.Context()); err != nil {
-				w.WriteHeader(http.StatusServiceUnavailable)
-				_, _ = w.Write([]byte(`{"message": "Health Unavailable"}`))
-				return
+			err := c.Healthy(r.Context())
+			if err == nil {
+				continue
 			}
+			var d interface{ Degraded() bool }
+			if errors.As(err, &d) && d.Degraded() {
+				degraded = true
+				continue
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message": "Health Unavailable"}`))
+			return
+		}
+		if degraded {
+			_, _ = w.Write([]byte(`{"message": "Health Degraded"}`))
+			return
 		}
 		_, _ = w.Write([]byte(`{"message": "Health OK"}`))
 	})
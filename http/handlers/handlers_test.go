@@ -60,6 +60,11 @@ func (m *mockHealthChecker) Healthy(_ context.Context) error {
 	return m.err
 }
 
+type degradedError struct{}
+
+func (degradedError) Error() string  { return "test-degraded" }
+func (degradedError) Degraded() bool { return true }
+
 func TestHandleHealth_WithCheckers(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -79,6 +84,18 @@ func TestHandleHealth_WithCheckers(t *testing.T) {
 			expectedBody: `{"message": "Health Unavailable"}`,
 			expectedCode: http.StatusServiceUnavailable,
 		},
+		{
+			name:         "one degraded",
+			checkers:     []HealthChecker{&mockHealthChecker{}, &mockHealthChecker{err: degradedError{}}},
+			expectedBody: `{"message": "Health Degraded"}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "degraded and unhealthy",
+			checkers:     []HealthChecker{&mockHealthChecker{err: degradedError{}}, &mockHealthChecker{err: errors.New("test-error")}},
+			expectedBody: `{"message": "Health Unavailable"}`,
+			expectedCode: http.StatusServiceUnavailable,
+		},
 		{
 			name:         "no checkers",
 			checkers:     nil,
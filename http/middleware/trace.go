@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Trace starts an OpenTelemetry server span for each request with otelhttp.
+// The span starts with the request's url.path and, once a ServeMux has matched
+// the request, its http.route, so trace samplers such as the config
+// RuleSampler can sample requests by path or route.
+//
+// To start spans with the route, wrap the handlers registered on the mux, e.g.
+// mux.Handle("GET /users/{id}", middleware.Trace(h)). Wrapped around the mux,
+// spans start with only the url.path.
+func Trace(next http.Handler, opts ...otelhttp.Option) http.Handler {
+	opts = append([]otelhttp.Option{otelhttp.WithSpanNameFormatter(traceSpanName)}, opts...)
+	return otelhttp.NewHandler(next, "", opts...)
+}
+
+// traceSpanName names spans "{method} {route}", or "{method}" until the
+// route is known, as the HTTP semantic conventions do.
+func traceSpanName(_ string, r *http.Request) string {
+	if r.Pattern == "" {
+		return r.Method
+	}
+	// Patterns registered with a method already start with it.
+	if strings.HasPrefix(r.Pattern, r.Method+" ") {
+		return r.Pattern
+	}
+	return r.Method + " " + r.Pattern
+}
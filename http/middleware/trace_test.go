@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// startSampler records the name and attributes of the spans it samples.
+type startSampler struct {
+	mu    sync.Mutex
+	name  string
+	attrs map[attribute.Key]string
+}
+
+func (s *startSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = p.Name
+	s.attrs = map[attribute.Key]string{}
+	for _, kv := range p.Attributes {
+		s.attrs[kv.Key] = kv.Value.Emit()
+	}
+	return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample}
+}
+
+func (s *startSampler) Description() string { return "startSampler" }
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		name      string
+		wrapMux   bool
+		path      string
+		wantName  string
+		wantRoute string
+		wantPath  string
+	}{
+		{
+			name:      "route",
+			path:      "/users/test-user",
+			wantName:  "GET /users/{id}",
+			wantRoute: "/users/{id}",
+			wantPath:  "/users/test-user",
+		},
+		{
+			name:     "mux",
+			wrapMux:  true,
+			path:     "/users/test-user",
+			wantName: "GET",
+			wantPath: "/users/test-user",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampler := &startSampler{}
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler))
+			opt := otelhttp.WithTracerProvider(tp)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			mux := http.NewServeMux()
+			var handler http.Handler = mux
+			if tt.wrapMux {
+				mux.Handle("GET /users/{id}", next)
+				handler = Trace(mux, opt)
+			} else {
+				mux.Handle("GET /users/{id}", Trace(next, opt))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if sampler.name != tt.wantName {
+				t.Errorf("span name at start: got %q, want %q", sampler.name, tt.wantName)
+			}
+			if got := sampler.attrs["http.route"]; got != tt.wantRoute {
+				t.Errorf("http.route at start: got %q, want %q", got, tt.wantRoute)
+			}
+			if got := sampler.attrs["url.path"]; got != tt.wantPath {
+				t.Errorf("url.path at start: got %q, want %q", got, tt.wantPath)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"sync/atomic"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
@@ -17,11 +18,26 @@ type Config struct {
 	Source *bool  `envconfig:"LOG_SOURCE" default:"true"  json:"source" yaml:"source"`
 }
 
+// defaultLevel is the level of the logger NewLogger last set as the default,
+// which SetLevel changes without rebuilding it.
+var defaultLevel atomic.Pointer[slog.LevelVar]
+
 // NewLogger sets and returns a new default logger configured by the provided Config.
 func NewLogger(cfg Config) *slog.Logger {
+	logger, level := NewLevelLogger(cfg)
+	defaultLevel.Store(level)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// NewLevelLogger returns a new logger configured by the provided Config, and
+// the level that controls it, without setting it as the default logger.
+func NewLevelLogger(cfg Config) (*slog.Logger, *slog.LevelVar) {
+	level := new(slog.LevelVar)
+	level.Set(cfg.LogLevel().Level())
 	handler := NewBaggageHandler(
 		NewOtelHandler(
-			cfg.LogFormat(),
+			cfg.handler(level),
 		),
 	)
 
@@ -34,9 +50,15 @@ func NewLogger(cfg Config) *slog.Logger {
 	if env, ok := os.LookupEnv("ENVIRONMENT"); ok {
 		logger = logger.With("env", env)
 	}
+	return logger, level
+}
 
-	slog.SetDefault(logger)
-	return logger
+// SetLevel updates the level of the default logger created by NewLogger from
+// the config. Loggers created by NewLevelLogger keep their own level.
+func SetLevel(cfg Config) {
+	if level := defaultLevel.Load(); level != nil {
+		level.Set(cfg.LogLevel().Level())
+	}
 }
 
 // LogLevel returns the slog level from the config. Defaults to INFO.
@@ -83,15 +105,19 @@ func (c Config) LogSource() bool {
 
 // LogFormat returns the slog handler based on the config format. Defaults to JSON.
 func (c Config) LogFormat() slog.Handler {
+	return c.handler(c.LogLevel())
+}
+
+func (c Config) handler(level slog.Leveler) slog.Handler {
 	switch strings.ToUpper(c.Format) {
 	case "TEXT":
 		return slog.NewTextHandler(c.LogOutput(), &slog.HandlerOptions{
-			Level:     c.LogLevel(),
+			Level:     level,
 			AddSource: c.LogSource(),
 		})
 	default:
 		return slog.NewJSONHandler(c.LogOutput(), &slog.HandlerOptions{
-			Level:     c.LogLevel(),
+			Level:     level,
 			AddSource: c.LogSource(),
 		})
 	}
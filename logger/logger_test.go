@@ -98,6 +98,43 @@ func TestNewLogger(t *testing.T) {
 	}
 }
 
+func TestSetLevel(t *testing.T) {
+	NewLogger(Config{Level: "INFO", Output: "STDERR"})
+	if slog.Default().Enabled(t.Context(), slog.LevelDebug) {
+		t.Fatal("debug logs should not be enabled at INFO")
+	}
+
+	SetLevel(Config{Level: "DEBUG"})
+	if !slog.Default().Enabled(t.Context(), slog.LevelDebug) {
+		t.Error("debug logs should be enabled after SetLevel(DEBUG)")
+	}
+
+	SetLevel(Config{Level: "ERROR"})
+	if slog.Default().Enabled(t.Context(), slog.LevelWarn) {
+		t.Error("warn logs should not be enabled after SetLevel(ERROR)")
+	}
+}
+
+func TestNewLevelLogger(t *testing.T) {
+	NewLogger(Config{Level: "INFO", Output: "STDERR"})
+	logger, level := NewLevelLogger(Config{Level: "WARN", Output: "STDERR"})
+
+	// Changing the default logger's level leaves other loggers alone.
+	SetLevel(Config{Level: "DEBUG"})
+	if logger.Enabled(t.Context(), slog.LevelInfo) {
+		t.Error("info logs should not be enabled at WARN after SetLevel(DEBUG)")
+	}
+
+	level.Set(slog.LevelDebug)
+	if !logger.Enabled(t.Context(), slog.LevelDebug) {
+		t.Error("debug logs should be enabled after LevelVar.Set(DEBUG)")
+	}
+	SetLevel(Config{Level: "ERROR"})
+	if !logger.Enabled(t.Context(), slog.LevelDebug) {
+		t.Error("debug logs should stay enabled after SetLevel(ERROR)")
+	}
+}
+
 func TestConfig_LogLevel(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var ErrRateLimited = status.Error(codes.ResourceExhausted, "rate limit exceeded")
+
+// UnaryServerInterceptor returns a gRPC unary server interceptor that rate
+// limits RPCs. Authenticated users get per-subject limits; unauthenticated
+// RPCs get per-peer IP limits.
+func (rl *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if res, ok := rl.allow(ctx, peerIP(ctx)); ok && !res.Allowed {
+			return nil, ErrRateLimited
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor that rate
+// limits opening streams. Authenticated users get per-subject limits;
+// unauthenticated streams get per-peer IP limits.
+func (rl *RateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if res, ok := rl.allow(ss.Context(), peerIP(ss.Context())); ok && !res.Allowed {
+			return ErrRateLimited
+		}
+		return handler(srv, ss)
+	}
+}
+
+// peerIP extracts the peer IP from the gRPC context.
+func peerIP(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return remoteIP(p.Addr.String())
+	}
+	return "unknown"
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/jesse0michael/pkg/auth"
+	"google.golang.org/grpc"
+	grpcpeer "google.golang.org/grpc/peer"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context { return f.ctx }
+
+func TestRateLimiter_UnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		limiter Limiter
+		ctx     context.Context
+		wantErr bool
+	}{
+		{
+			name:    "authenticated request allowed",
+			cfg:     Config{Rate: 10, Burst: 10, NoAuthRate: 1, NoAuthBurst: 1},
+			limiter: NewMemoryLimiter(),
+			ctx:     context.WithValue(t.Context(), auth.SubjectContextKey, "test-user"),
+		},
+		{
+			name:    "authenticated request rate limited",
+			cfg:     Config{Rate: 0, Burst: 1, NoAuthRate: 0, NoAuthBurst: 10},
+			limiter: NewMemoryLimiter(),
+			ctx:     context.WithValue(t.Context(), auth.SubjectContextKey, "test-user"),
+			wantErr: true,
+		},
+		{
+			name:    "unauthenticated request rate limited by peer",
+			cfg:     Config{Rate: 10, Burst: 10, NoAuthRate: 0, NoAuthBurst: 1},
+			limiter: NewMemoryLimiter(),
+			ctx: grpcpeer.NewContext(t.Context(), &grpcpeer.Peer{
+				Addr: &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 1234},
+			}),
+			wantErr: true,
+		},
+		{
+			name:    "limiter error allowed",
+			cfg:     Config{Rate: 0, Burst: 1, NoAuthRate: 0, NoAuthBurst: 1},
+			limiter: errLimiter{},
+			ctx:     t.Context(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := New(tt.cfg, tt.limiter).UnaryServerInterceptor()
+			handler := func(_ context.Context, _ any) (any, error) { return "ok", nil }
+			info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+			var err error
+			for range 3 {
+				_, err = interceptor(tt.ctx, nil, info, handler)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got err=%v, wantErr=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_StreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		ctx     context.Context
+		wantErr bool
+	}{
+		{
+			name: "stream allowed",
+			cfg:  Config{Rate: 10, Burst: 10, NoAuthRate: 1, NoAuthBurst: 1},
+			ctx:  context.WithValue(t.Context(), auth.SubjectContextKey, "test-user"),
+		},
+		{
+			name:    "stream rate limited",
+			cfg:     Config{Rate: 0, Burst: 1, NoAuthRate: 0, NoAuthBurst: 10},
+			ctx:     context.WithValue(t.Context(), auth.SubjectContextKey, "test-user"),
+			wantErr: true,
+		},
+		{
+			name: "stream unauthenticated rate limited",
+			cfg:  Config{Rate: 10, Burst: 10, NoAuthRate: 0, NoAuthBurst: 1},
+			ctx: grpcpeer.NewContext(t.Context(), &grpcpeer.Peer{
+				Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1000},
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := New(tt.cfg, NewMemoryLimiter()).StreamServerInterceptor()
+			ss := &fakeServerStream{ctx: tt.ctx}
+			handler := func(_ any, _ grpc.ServerStream) error { return nil }
+			info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Method"}
+
+			var err error
+			for range 3 {
+				err = interceptor(nil, ss, info, handler)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got err=%v, wantErr=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
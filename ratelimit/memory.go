@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// MemoryLimiter is an in-memory token bucket Limiter for a single instance.
+// Keys are evicted once they've been idle long enough to refill, when
+// forgetting them doesn't change their limit, so the limiter doesn't grow with
+// every key it has seen. Keys of limits that never refill aren't evicted.
+type MemoryLimiter struct {
+	mu            sync.Mutex
+	limiters      map[string]*rate.Limiter
+	sweepInterval time.Duration
+	lastSweep     time.Time
+}
+
+// NewMemoryLimiter creates an in-memory Limiter.
+func NewMemoryLimiter(opts ...Option) *MemoryLimiter {
+	o := newOptions(opts)
+	return &MemoryLimiter{
+		limiters:      map[string]*rate.Limiter{},
+		sweepInterval: o.sweepInterval,
+		lastSweep:     time.Now(),
+	}
+}
+
+// Allow takes a request for key from its limit.
+func (m *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.lastSweep) >= m.sweepInterval {
+		m.sweep(now)
+	}
+
+	l, ok := m.limiters[key]
+	if !ok {
+		l = rate.NewLimiter(limit.Rate, limit.Burst)
+		m.limiters[key] = l
+	}
+	if l.Limit() != limit.Rate {
+		l.SetLimitAt(now, limit.Rate)
+	}
+	if l.Burst() != limit.Burst {
+		l.SetBurstAt(now, limit.Burst)
+	}
+
+	res := Result{Allowed: l.AllowN(now, 1), Limit: limit.Burst}
+	tokens := l.TokensAt(now)
+	res.Remaining = max(int(tokens), 0)
+	if limit.Rate > 0 && limit.Rate != rate.Inf {
+		if !res.Allowed {
+			res.RetryAfter = tokenDuration(1-tokens, limit.Rate)
+		}
+		res.ResetAfter = tokenDuration(float64(limit.Burst)-tokens, limit.Rate)
+	}
+	return res, nil
+}
+
+// Len returns the number of keys being tracked.
+func (m *MemoryLimiter) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.limiters)
+}
+
+func (m *MemoryLimiter) sweep(now time.Time) {
+	for key, l := range m.limiters {
+		if l.TokensAt(now) >= float64(l.Burst()) {
+			delete(m.limiters, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// tokenDuration returns how long r takes to refill tokens.
+func tokenDuration(tokens float64, r rate.Limit) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens / float64(r) * float64(time.Second)))
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          Limit
+		requests       int
+		wantAllowed    bool
+		wantRemaining  int
+		wantRetryAfter bool
+	}{
+		{
+			name:          "allowed",
+			limit:         Limit{Rate: 1, Burst: 3},
+			requests:      2,
+			wantAllowed:   true,
+			wantRemaining: 1,
+		},
+		{
+			name:           "rate limited",
+			limit:          Limit{Rate: 1, Burst: 2},
+			requests:       3,
+			wantRemaining:  0,
+			wantRetryAfter: true,
+		},
+		{
+			name:     "rate limited without refill",
+			limit:    Limit{Rate: 0, Burst: 1},
+			requests: 2,
+		},
+		{
+			name:          "unlimited",
+			limit:         Limit{Rate: rate.Inf},
+			requests:      3,
+			wantAllowed:   true,
+			wantRemaining: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemoryLimiter()
+			var res Result
+			var err error
+			for range tt.requests {
+				res, err = m.Allow(t.Context(), "test-key", tt.limit)
+			}
+			if err != nil {
+				t.Fatalf("MemoryLimiter.Allow() error = %v", err)
+			}
+			if res.Allowed != tt.wantAllowed || res.Remaining != tt.wantRemaining {
+				t.Errorf("MemoryLimiter.Allow() = %+v, want allowed %v, remaining %d", res, tt.wantAllowed, tt.wantRemaining)
+			}
+			if (res.RetryAfter > 0) != tt.wantRetryAfter {
+				t.Errorf("MemoryLimiter.Allow() RetryAfter = %v, want %v", res.RetryAfter, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestMemoryLimiter_Sweep(t *testing.T) {
+	m := NewMemoryLimiter(WithSweepInterval(10 * time.Millisecond))
+	ctx := t.Context()
+	_, _ = m.Allow(ctx, "test-key-1", Limit{Rate: 1000, Burst: 1})
+	_, _ = m.Allow(ctx, "test-key-2", Limit{Rate: 0, Burst: 1})
+	if m.Len() != 2 {
+		t.Fatalf("MemoryLimiter.Len() = %d, want 2", m.Len())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	_, _ = m.Allow(ctx, "test-key-3", Limit{Rate: 1, Burst: 1})
+
+	// test-key-1 refilled and was evicted, test-key-2 never refills.
+	if m.Len() != 2 {
+		t.Errorf("MemoryLimiter.Len() = %d, want 2", m.Len())
+	}
+	if res, _ := m.Allow(ctx, "test-key-2", Limit{Rate: 0, Burst: 1}); res.Allowed {
+		t.Error("MemoryLimiter.Allow() test-key-2 allowed after sweep")
+	}
+}
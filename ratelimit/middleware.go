@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jesse0michael/pkg/auth"
+)
+
+// RateLimiter rate limits HTTP requests and gRPC RPCs with a Limiter.
+// Authenticated users are keyed by auth.Subject; unauthenticated requests are
+// keyed by client IP and get the NoAuth limits. Requests are allowed when the
+// Limiter fails, so a Redis outage doesn't take the service down with it.
+type RateLimiter struct {
+	cfg     Config
+	limiter Limiter
+}
+
+// New creates a RateLimiter with the given configuration, limiting requests
+// with limiter.
+func New(cfg Config, limiter Limiter) *RateLimiter {
+	return &RateLimiter{
+		cfg:     cfg,
+		limiter: limiter,
+	}
+}
+
+// Middleware rate limits requests to next. Responses carry RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, and rejected requests get
+// 429 Too Many Requests with a Retry-After header. The client IP is the
+// request's remote address; run behind proxies with middleware that sets it
+// from a trusted forwarding header.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, ok := rl.allow(r.Context(), remoteIP(r.RemoteAddr))
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("RateLimit-Reset", seconds(res.ResetAfter))
+		if !res.Allowed {
+			if res.RetryAfter > 0 {
+				w.Header().Set("Retry-After", seconds(res.RetryAfter))
+			}
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a request from the limit of the subject in ctx, or of ip. It
+// returns false when the limiter fails and the request is let through.
+func (rl *RateLimiter) allow(ctx context.Context, ip string) (Result, bool) {
+	key, limit := "ip:"+ip, Limit{Rate: rl.cfg.NoAuthRate, Burst: rl.cfg.NoAuthBurst}
+	if subject, ok := auth.Subject(ctx); ok {
+		key, limit = "subject:"+subject, Limit{Rate: rl.cfg.Rate, Burst: rl.cfg.Burst}
+	}
+
+	res, err := rl.limiter.Allow(ctx, key, limit)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to rate limit request", "err", err, "key", key)
+		return res, false
+	}
+	return res, true
+}
+
+// remoteIP strips the port from a remote address.
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// seconds rounds d up to whole seconds, as rate limit headers expect.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jesse0michael/pkg/auth"
+)
+
+type errLimiter struct{}
+
+func (errLimiter) Allow(context.Context, string, Limit) (Result, error) {
+	return Result{}, errors.New("test-error")
+}
+
+func TestRateLimiter_Middleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            Config
+		limiter        Limiter
+		ctx            context.Context
+		remoteAddr     string
+		wantStatus     int
+		wantRemaining  string
+		wantRetryAfter string
+	}{
+		{
+			name:          "authenticated request allowed",
+			cfg:           Config{Rate: 10, Burst: 10, NoAuthRate: 1, NoAuthBurst: 1},
+			limiter:       NewMemoryLimiter(),
+			ctx:           context.WithValue(t.Context(), auth.SubjectContextKey, "test-user"),
+			remoteAddr:    "192.168.1.1:1234",
+			wantStatus:    http.StatusOK,
+			wantRemaining: "7",
+		},
+		{
+			name:           "authenticated request rate limited",
+			cfg:            Config{Rate: 1, Burst: 2, NoAuthRate: 10, NoAuthBurst: 10},
+			limiter:        NewMemoryLimiter(),
+			ctx:            context.WithValue(t.Context(), auth.SubjectContextKey, "test-user"),
+			remoteAddr:     "192.168.1.1:1234",
+			wantStatus:     http.StatusTooManyRequests,
+			wantRemaining:  "0",
+			wantRetryAfter: "1",
+		},
+		{
+			name:           "unauthenticated request rate limited by ip",
+			cfg:            Config{Rate: 10, Burst: 10, NoAuthRate: 1, NoAuthBurst: 1},
+			limiter:        NewMemoryLimiter(),
+			ctx:            t.Context(),
+			remoteAddr:     "192.168.1.1:1234",
+			wantStatus:     http.StatusTooManyRequests,
+			wantRemaining:  "0",
+			wantRetryAfter: "1",
+		},
+		{
+			name:       "limiter error allowed",
+			cfg:        Config{Rate: 10, Burst: 10, NoAuthRate: 1, NoAuthBurst: 1},
+			limiter:    errLimiter{},
+			ctx:        t.Context(),
+			remoteAddr: "192.168.1.1:1234",
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(tt.cfg, tt.limiter).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			var w *httptest.ResponseRecorder
+			for i := range 3 {
+				w = httptest.NewRecorder()
+				r := httptest.NewRequestWithContext(tt.ctx, http.MethodGet, "/test", nil)
+				// Each request comes from a new port of the same client.
+				r.RemoteAddr = tt.remoteAddr[:len(tt.remoteAddr)-1] + string(rune('0'+i))
+				h.ServeHTTP(w, r)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("RateLimit-Remaining"); got != tt.wantRemaining {
+				t.Errorf("RateLimit-Remaining = %q, want %q", got, tt.wantRemaining)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if tt.wantRemaining != "" && (w.Header().Get("RateLimit-Limit") == "" || w.Header().Get("RateLimit-Reset") == "") {
+				t.Errorf("RateLimit headers = %v", w.Header())
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limit allows Burst requests at once, refilled at Rate requests per second.
+type Limit struct {
+	Rate  rate.Limit
+	Burst int
+}
+
+// Result is the outcome of a rate limited request.
+type Result struct {
+	Allowed bool
+	// Limit is the burst of the key's limit.
+	Limit int
+	// Remaining is the number of requests the key may make right away.
+	Remaining int
+	// RetryAfter is how long until a rejected request would be allowed. It's
+	// 0 for allowed requests and for limits that never refill.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the key's limit is fully refilled.
+	ResetAfter time.Duration
+}
+
+// Limiter rate limits requests by key.
+type Limiter interface {
+	// Allow takes a request for key from its limit.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Config configures the rate limits of a RateLimiter.
+type Config struct {
+	// Rate is the token refill rate (per second) for authenticated users.
+	// Default ~1.67/s = 100 requests per minute.
+	Rate rate.Limit `envconfig:"RATE_LIMIT_RATE" default:"1.67"`
+	// Burst is the maximum burst size for authenticated users.
+	Burst int `envconfig:"RATE_LIMIT_BURST" default:"100"`
+	// NoAuthRate is the token refill rate for unauthenticated requests,
+	// keyed by client IP. Default ~0.17/s = 10 requests per minute.
+	NoAuthRate rate.Limit `envconfig:"RATE_LIMIT_NO_AUTH_RATE" default:"0.17"`
+	// NoAuthBurst is the maximum burst size for unauthenticated requests, keyed by client IP.
+	NoAuthBurst int `envconfig:"RATE_LIMIT_NO_AUTH_BURST" default:"20"`
+}
+
+// Option configures a RateLimiter, MemoryLimiter or RedisLimiter.
+type Option func(*options)
+
+type options struct {
+	sweepInterval time.Duration
+	prefix        string
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		sweepInterval: time.Minute,
+		prefix:        "ratelimit:",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithSweepInterval sets how often a MemoryLimiter evicts idle keys, every
+// minute by default.
+func WithSweepInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.sweepInterval = d
+	}
+}
+
+// WithPrefix sets the prefix of RedisLimiter keys, "ratelimit:" by default.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
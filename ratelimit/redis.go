@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+// gcraScript implements the generic cell rate algorithm. KEYS[1] holds the
+// theoretical arrival time (TAT) in milliseconds: when the key's limit would
+// be fully refilled. A request is allowed unless it would push the TAT more
+// than a burst of intervals past now. ARGV[1] is the emission interval in
+// milliseconds and ARGV[2] the burst. The Redis clock is used so replicas
+// agree on time. Reading it before a write needs the script's effects, rather
+// than the script, to be replicated: the default from Redis 5, and enabled by
+// redis.replicate_commands from Redis 3.2. Fractions are returned as strings,
+// which Redis would otherwise truncate.
+var gcraScript = redis.NewScript(`redis.replicate_commands()
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call("time")
+local now = tonumber(time[1]) * 1000 + tonumber(time[2]) / 1000
+local tat = math.max(tonumber(redis.call("get", KEYS[1])) or now, now)
+local tolerance = interval * burst
+local next_tat = tat + interval
+local allow_at = next_tat - tolerance
+if allow_at > now then
+	return {0, 0, tostring(allow_at - now), tostring(tat - now)}
+end
+redis.call("set", KEYS[1], tostring(next_tat), "px", math.ceil(next_tat - now))
+return {1, math.floor((now - allow_at) / interval), "0", tostring(next_tat - now)}`)
+
+// RedisLimiter is a Limiter that keeps limits in Redis with the generic cell
+// rate algorithm, so they hold across replicas. Each key is a single Redis
+// string that expires once its limit is refilled.
+type RedisLimiter struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisLimiter creates a Limiter storing limits in client.
+func NewRedisLimiter(client redis.Scripter, opts ...Option) *RedisLimiter {
+	o := newOptions(opts)
+	return &RedisLimiter{
+		client: client,
+		prefix: o.prefix,
+	}
+}
+
+// Allow takes a request for key from its limit. Limits must refill, with a
+// positive Rate.
+func (r *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Rate == rate.Inf {
+		return Result{Allowed: true, Limit: limit.Burst, Remaining: limit.Burst}, nil
+	}
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		return Result{}, errors.New("failed to rate limit: rate and burst must be positive")
+	}
+
+	interval := 1000 / float64(limit.Rate)
+	values, err := gcraScript.Run(ctx, r.client, []string{r.prefix + key},
+		strconv.FormatFloat(interval, 'f', -1, 64), limit.Burst).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to rate limit: %w", err)
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("failed to rate limit: unexpected result %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	return Result{
+		Allowed:    allowed == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(remaining),
+		RetryAfter: milliseconds(values[2]),
+		ResetAfter: milliseconds(values[3]),
+	}, nil
+}
+
+func milliseconds(v any) time.Duration {
+	s, _ := v.(string)
+	ms, _ := strconv.ParseFloat(s, 64)
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          Limit
+		requests       int
+		wantAllowed    bool
+		wantRemaining  int
+		wantRetryAfter time.Duration
+		wantResetAfter time.Duration
+		wantErr        bool
+	}{
+		{
+			name:           "allowed",
+			limit:          Limit{Rate: 1, Burst: 3},
+			requests:       2,
+			wantAllowed:    true,
+			wantRemaining:  1,
+			wantResetAfter: 2 * time.Second,
+		},
+		{
+			name:           "rate limited",
+			limit:          Limit{Rate: 2, Burst: 2},
+			requests:       3,
+			wantRetryAfter: 500 * time.Millisecond,
+			wantResetAfter: time.Second,
+		},
+		{
+			name:          "unlimited",
+			limit:         Limit{Rate: rate.Inf, Burst: 1},
+			requests:      3,
+			wantAllowed:   true,
+			wantRemaining: 1,
+		},
+		{
+			name:     "no refill",
+			limit:    Limit{Rate: 0, Burst: 1},
+			requests: 1,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := miniredis.RunT(t)
+			s.SetTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+			r := NewRedisLimiter(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+
+			var res Result
+			var err error
+			for range tt.requests {
+				res, err = r.Allow(t.Context(), "test-key", tt.limit)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RedisLimiter.Allow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Allowed != tt.wantAllowed || res.Remaining != tt.wantRemaining {
+				t.Errorf("RedisLimiter.Allow() = %+v, want allowed %v, remaining %d", res, tt.wantAllowed, tt.wantRemaining)
+			}
+			if res.RetryAfter != tt.wantRetryAfter || res.ResetAfter != tt.wantResetAfter {
+				t.Errorf("RedisLimiter.Allow() = %+v, want retry after %v, reset after %v", res, tt.wantRetryAfter, tt.wantResetAfter)
+			}
+		})
+	}
+}
+
+func TestRedisLimiter_Refill(t *testing.T) {
+	s := miniredis.RunT(t)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.SetTime(now)
+	r := NewRedisLimiter(redis.NewClient(&redis.Options{Addr: s.Addr()}), WithPrefix("test-prefix:"))
+	ctx := t.Context()
+	limit := Limit{Rate: 10, Burst: 1}
+
+	if res, _ := r.Allow(ctx, "test-key", limit); !res.Allowed {
+		t.Fatal("RedisLimiter.Allow() first request rejected")
+	}
+	if res, _ := r.Allow(ctx, "test-key", limit); res.Allowed {
+		t.Fatal("RedisLimiter.Allow() second request allowed")
+	}
+	if !s.Exists("test-prefix:test-key") {
+		t.Error("RedisLimiter key test-prefix:test-key not set")
+	}
+
+	s.SetTime(now.Add(100 * time.Millisecond))
+	if res, _ := r.Allow(ctx, "test-key", limit); !res.Allowed {
+		t.Error("RedisLimiter.Allow() rejected after refill")
+	}
+}
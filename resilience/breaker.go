@@ -0,0 +1,144 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Config tunes the circuit breakers of a Breaker.
+type Config struct {
+	// Timeout is how long a breaker stays open before probing again.
+	Timeout time.Duration
+	// Failures trips a breaker after more than this many consecutive failures.
+	Failures uint32
+	// FailureRatio, when set, trips a breaker once this ratio of at least
+	// MinRequests calls fail.
+	FailureRatio float64
+	// MinRequests is the number of calls FailureRatio needs before it trips.
+	MinRequests uint32
+	// Interval clears the counts of a closed breaker this often. 0 never clears them.
+	Interval time.Duration
+	// MaxRequests is the number of calls let through to probe a half-open breaker.
+	MaxRequests uint32
+}
+
+// readyToTrip trips after more than Config.Failures consecutive failures or,
+// when Config.FailureRatio is set, once that ratio of at least
+// Config.MinRequests calls fail. It returns nil, the gobreaker default of more
+// than 5 consecutive failures, when neither is set.
+func readyToTrip(cfg Config) func(gobreaker.Counts) bool {
+	if cfg.Failures == 0 && cfg.FailureRatio <= 0 {
+		return nil
+	}
+	return func(counts gobreaker.Counts) bool {
+		if cfg.Failures > 0 && counts.ConsecutiveFailures > cfg.Failures {
+			return true
+		}
+		return cfg.FailureRatio > 0 && counts.Requests >= cfg.MinRequests &&
+			float64(counts.TotalFailures)/float64(counts.Requests) >= cfg.FailureRatio
+	}
+}
+
+// Breaker keeps a circuit breaker per key, such as a host or an RPC method, so
+// a failing dependency fails fast without affecting calls to the others. State
+// changes are logged, each key's state (0 closed, 1 half-open, 2 open) is
+// recorded as the <name>.breaker.state metric and rejected calls are counted
+// by the <name>.breaker.rejected metric, both with the key as the key
+// attribute.
+type Breaker struct {
+	name         string
+	key          attribute.Key
+	cfg          Config
+	mu           sync.Mutex
+	breakers     map[string]*gobreaker.CircuitBreaker
+	rejected     metric.Int64Counter
+	registration metric.Registration
+}
+
+// NewBreaker creates a Breaker whose metrics are named after name, e.g.
+// http.client, and attributed with key, e.g. server.address.
+func NewBreaker(name string, key attribute.Key, cfg Config, opts ...Option) *Breaker {
+	o := newOptions(opts)
+	b := &Breaker{
+		name:     name,
+		key:      key,
+		cfg:      cfg,
+		breakers: map[string]*gobreaker.CircuitBreaker{},
+	}
+
+	meter := o.meterProvider.Meter(meterName)
+	var err error
+	if b.rejected, err = meter.Int64Counter(name+".breaker.rejected",
+		metric.WithDescription("Number of calls rejected by an open circuit breaker")); err != nil {
+		otel.Handle(err)
+	}
+	state, err := meter.Int64ObservableGauge(name+".breaker.state",
+		metric.WithDescription("State of the circuit breaker: 0 closed, 1 half-open, 2 open"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	if b.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for k, cb := range b.breakers {
+			o.ObserveInt64(state, int64(cb.State()), metric.WithAttributes(b.key.String(k)))
+		}
+		return nil
+	}, state); err != nil {
+		otel.Handle(err)
+	}
+	return b
+}
+
+// CircuitBreaker returns the circuit breaker of key, creating it on first use.
+func (b *Breaker) CircuitBreaker(key string) *gobreaker.CircuitBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cb, ok := b.breakers[key]
+	if !ok {
+		cb = gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:        key,
+			MaxRequests: b.cfg.MaxRequests,
+			Interval:    b.cfg.Interval,
+			Timeout:     b.cfg.Timeout,
+			ReadyToTrip: readyToTrip(b.cfg),
+			OnStateChange: func(key string, from, to gobreaker.State) {
+				slog.Warn("circuit breaker changing state", "breaker", b.name, string(b.key), key,
+					"from", from.String(), "to", to.String())
+			},
+		})
+		b.breakers[key] = cb
+	}
+	return cb
+}
+
+// State returns the breaker state of key.
+func (b *Breaker) State(key string) gobreaker.State {
+	return b.CircuitBreaker(key).State()
+}
+
+// Execute runs req through the breaker of key, counting the calls it rejects.
+// An error returned by req counts as a failure.
+func (b *Breaker) Execute(ctx context.Context, key string, req func() (any, error)) (any, error) {
+	result, err := b.CircuitBreaker(key).Execute(req)
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		b.rejected.Add(ctx, 1, metric.WithAttributes(b.key.String(key)))
+	}
+	return result, err
+}
+
+// Close stops recording the breaker state metric.
+func (b *Breaker) Close() error {
+	if b.registration == nil {
+		return nil
+	}
+	return b.registration.Unregister()
+}
@@ -0,0 +1,114 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestBreaker_ReadyToTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		successes int
+		failures  int
+		wantState gobreaker.State
+	}{
+		{
+			name:      "default trips after 5 consecutive failures",
+			cfg:       Config{},
+			failures:  6,
+			wantState: gobreaker.StateOpen,
+		},
+		{
+			name:      "below consecutive failures",
+			cfg:       Config{Failures: 3},
+			failures:  3,
+			wantState: gobreaker.StateClosed,
+		},
+		{
+			name:      "consecutive failures",
+			cfg:       Config{Failures: 3},
+			failures:  4,
+			wantState: gobreaker.StateOpen,
+		},
+		{
+			name:      "failure ratio",
+			cfg:       Config{Failures: 100, FailureRatio: 0.5, MinRequests: 4},
+			successes: 2,
+			failures:  2,
+			wantState: gobreaker.StateOpen,
+		},
+		{
+			name:      "failure ratio below min requests",
+			cfg:       Config{Failures: 100, FailureRatio: 0.5, MinRequests: 10},
+			successes: 2,
+			failures:  2,
+			wantState: gobreaker.StateClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker("test", "test.key", tt.cfg)
+			for range tt.successes {
+				_, _ = b.Execute(t.Context(), "test-key", func() (any, error) { return nil, nil })
+			}
+			for range tt.failures {
+				_, _ = b.Execute(t.Context(), "test-key", func() (any, error) { return nil, errors.New("test-error") })
+			}
+			if got := b.State("test-key"); got != tt.wantState {
+				t.Errorf("Breaker.State() = %v, want %v", got, tt.wantState)
+			}
+			if got := b.State("test-other"); got != gobreaker.StateClosed {
+				t.Errorf("Breaker.State() other key = %v, want closed", got)
+			}
+		})
+	}
+}
+
+func TestBreaker_Metrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	b := NewBreaker("test", "test.key", Config{Failures: 1, Timeout: time.Hour},
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	for range 3 {
+		_, _ = b.Execute(t.Context(), "test-key", func() (any, error) { return nil, errors.New("test-error") })
+	}
+
+	metrics := collect(t, reader)
+	if got := metrics["test.breaker.state"].(metricdata.Gauge[int64]).DataPoints[0].Value; got != int64(gobreaker.StateOpen) {
+		t.Errorf("test.breaker.state = %d, want %d", got, gobreaker.StateOpen)
+	}
+	dp := metrics["test.breaker.rejected"].(metricdata.Sum[int64]).DataPoints[0]
+	if dp.Value != 1 {
+		t.Errorf("test.breaker.rejected = %d, want 1", dp.Value)
+	}
+	if v, _ := dp.Attributes.Value("test.key"); v.AsString() != "test-key" {
+		t.Errorf("test.breaker.rejected test.key = %q, want test-key", v.AsString())
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("Breaker.Close() error = %v", err)
+	}
+	if _, ok := collect(t, reader)["test.breaker.state"]; ok {
+		t.Error("test.breaker.state recorded after Close()")
+	}
+}
+
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
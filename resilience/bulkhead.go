@@ -0,0 +1,67 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrBulkheadFull is returned when a key already has the maximum number of
+// calls in flight.
+var ErrBulkheadFull = errors.New("bulkhead full")
+
+// Bulkhead caps the number of calls in flight per key, so a slow dependency
+// can't tie up every connection and goroutine. Calls over the cap fail
+// immediately with ErrBulkheadFull and are counted by the
+// <name>.bulkhead.rejected metric, with the key as the key attribute.
+type Bulkhead struct {
+	key      attribute.Key
+	max      int
+	mu       sync.Mutex
+	active   map[string]int
+	rejected metric.Int64Counter
+}
+
+// NewBulkhead creates a bulkhead allowing up to maxConcurrent calls in flight
+// per key, or any number when it's 0. Its metric is named after name and
+// attributed with key, like a Breaker's.
+func NewBulkhead(name string, key attribute.Key, maxConcurrent int, opts ...Option) *Bulkhead {
+	o := newOptions(opts)
+	b := &Bulkhead{
+		key:    key,
+		max:    maxConcurrent,
+		active: map[string]int{},
+	}
+	var err error
+	if b.rejected, err = o.meterProvider.Meter(meterName).Int64Counter(name+".bulkhead.rejected",
+		metric.WithDescription("Number of calls rejected by a full bulkhead")); err != nil {
+		otel.Handle(err)
+	}
+	return b
+}
+
+// Acquire takes a slot for a call to key, returning the func that frees it.
+func (b *Bulkhead) Acquire(ctx context.Context, key string) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.max > 0 && b.active[key] >= b.max {
+		b.rejected.Add(ctx, 1, metric.WithAttributes(b.key.String(key)))
+		return nil, ErrBulkheadFull
+	}
+	b.active[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.active[key]--; b.active[key] <= 0 {
+				delete(b.active, key)
+			}
+		})
+	}, nil
+}
@@ -0,0 +1,76 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestBulkhead_Acquire(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		acquire       int
+		wantErr       error
+	}{
+		{
+			name:          "below max",
+			maxConcurrent: 2,
+			acquire:       2,
+		},
+		{
+			name:          "full",
+			maxConcurrent: 2,
+			acquire:       3,
+			wantErr:       ErrBulkheadFull,
+		},
+		{
+			name:          "unlimited",
+			maxConcurrent: 0,
+			acquire:       10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBulkhead("test", "test.key", tt.maxConcurrent)
+			var err error
+			for range tt.acquire {
+				_, err = b.Acquire(t.Context(), "test-key")
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Bulkhead.Acquire() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := b.Acquire(t.Context(), "test-other"); err != nil {
+				t.Errorf("Bulkhead.Acquire() other key error = %v", err)
+			}
+		})
+	}
+}
+
+func TestBulkhead_Release(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	b := NewBulkhead("test", "test.key", 1, WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+
+	release, err := b.Acquire(t.Context(), "test-key")
+	if err != nil {
+		t.Fatalf("Bulkhead.Acquire() error = %v", err)
+	}
+	if _, err := b.Acquire(t.Context(), "test-key"); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Bulkhead.Acquire() error = %v, want ErrBulkheadFull", err)
+	}
+	if got := collect(t, reader)["test.bulkhead.rejected"].(metricdata.Sum[int64]).DataPoints[0].Value; got != 1 {
+		t.Errorf("test.bulkhead.rejected = %d, want 1", got)
+	}
+
+	// Releasing twice frees the slot once.
+	release()
+	release()
+	if _, err := b.Acquire(t.Context(), "test-key"); err != nil {
+		t.Errorf("Bulkhead.Acquire() after release error = %v", err)
+	}
+	if _, err := b.Acquire(t.Context(), "test-key"); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Bulkhead.Acquire() after double release error = %v, want ErrBulkheadFull", err)
+	}
+}
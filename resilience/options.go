@@ -0,0 +1,31 @@
+package resilience
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/jesse0michael/pkg/resilience"
+
+// Option configures a Breaker or Bulkhead.
+type Option func(*options)
+
+type options struct {
+	meterProvider metric.MeterProvider
+}
+
+func newOptions(opts []Option) options {
+	o := options{meterProvider: otel.GetMeterProvider()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMeterProvider sets the meter provider that records breaker and bulkhead
+// metrics, the global meter provider by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = mp
+	}
+}